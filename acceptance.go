@@ -0,0 +1,124 @@
+package hx
+
+import (
+    "math"
+    "math/rand"
+)
+
+// Acceptance criteria
+//-------------------------------
+
+// AcceptanceDecision is what an AcceptanceCriterion decides to do with a
+// candidate solution.
+type AcceptanceDecision int
+
+const (
+    // Reject keeps the current solution.
+    Reject AcceptanceDecision = iota
+    // Accept makes the candidate the current solution.
+    Accept
+    // Restart abandons the current solution and restarts the search.
+    Restart
+)
+
+// AcceptanceCriterion decides whether a candidate replaces the current
+// solution of an iterated algorithm. nonImprovingIter is the number of
+// iterations since the best solution last improved.
+type AcceptanceCriterion interface {
+    Accept(currentCost float64, candidateCost float64, bestCost float64, nonImprovingIter int) AcceptanceDecision
+}
+
+// BetterAcceptance only accepts candidates better than the current solution.
+type BetterAcceptance struct{}
+
+func (c *BetterAcceptance) Accept(currentCost float64, candidateCost float64, bestCost float64, nonImprovingIter int) AcceptanceDecision {
+    if fitnessDiff(currentCost, candidateCost) >= ZERO {
+        return Accept
+    }
+    return Reject
+}
+
+// RandomWalkAcceptance accepts every candidate.
+type RandomWalkAcceptance struct{}
+
+func (c *RandomWalkAcceptance) Accept(currentCost float64, candidateCost float64, bestCost float64, nonImprovingIter int) AcceptanceDecision {
+    return Accept
+}
+
+// RestartAcceptance only accepts better candidates and asks for a restart
+// after After iterations without improving the best solution. It never asks
+// for one when After is 0.
+type RestartAcceptance struct {
+    After int
+}
+
+func (c *RestartAcceptance) Accept(currentCost float64, candidateCost float64, bestCost float64, nonImprovingIter int) AcceptanceDecision {
+    if c.After > 0 && nonImprovingIter > 0 && nonImprovingIter % c.After == 0 {
+        return Restart
+    }
+    if fitnessDiff(currentCost, candidateCost) >= ZERO {
+        return Accept
+    }
+    return Reject
+}
+
+// SAAcceptance accepts worse candidates with the Metropolis probability at
+// Temperature, which is multiplied by 1-CoolingRate after every decision.
+type SAAcceptance struct {
+    Temperature float64
+    CoolingRate float64
+}
+
+func (c *SAAcceptance) Accept(currentCost float64, candidateCost float64, bestCost float64, nonImprovingIter int) AcceptanceDecision {
+    defer func() { c.Temperature *= (1-c.CoolingRate) }()
+    return MetropolisDecision(fitnessDiff(candidateCost, currentCost), c.Temperature)
+}
+
+// LSMCAcceptance is the acceptance of large-step Markov chains: the
+// Metropolis criterion at a constant Temperature.
+type LSMCAcceptance struct {
+    Temperature float64
+}
+
+func (c *LSMCAcceptance) Accept(currentCost float64, candidateCost float64, bestCost float64, nonImprovingIter int) AcceptanceDecision {
+    return MetropolisDecision(fitnessDiff(candidateCost, currentCost), c.Temperature)
+}
+
+// MetropolisDecision accepts improvements and worsenings of costDiff with
+// probability exp(-costDiff/temperature).
+func MetropolisDecision(costDiff float64, temperature float64) AcceptanceDecision {
+    if costDiff < 0.0 || rand.Float64() < math.Exp(-costDiff/temperature) {
+        return Accept
+    }
+    return Reject
+}
+
+// LateAcceptance accepts candidates no worse than the current solution or
+// than the current solution of Length decisions ago.
+type LateAcceptance struct {
+    Length    int
+    History   []float64
+    Decisions int
+}
+
+func (c *LateAcceptance) Accept(currentCost float64, candidateCost float64, bestCost float64, nonImprovingIter int) AcceptanceDecision {
+    if len(c.History) == 0 {
+        c.History = make([]float64, Max(c.Length, 1))
+        for i := range c.History {
+            c.History[i] = currentCost
+        }
+    }
+
+    v := c.Decisions % len(c.History)
+    late := c.History[v]
+    c.Decisions++
+
+    decision := Reject
+    if candidateCost <= late || candidateCost <= currentCost {
+        decision = Accept
+        currentCost = candidateCost
+    }
+
+    c.History[v] = currentCost
+    return decision
+}
@@ -0,0 +1,133 @@
+package hx
+
+import (
+    "reflect"
+)
+
+// Problem adapter
+//-------------------------------
+
+// Problem turns an arbitrary state type S into a solution type, State[S],
+// given its objective function and a function copying it. Equal and Hash
+// are optional: without Equal states are compared with reflect.DeepEqual,
+// and without Hash all states share the same hash, so that a SolutionMap,
+// as used by the tabu list and the cycle detection of TSAlg, scans all of
+// its states with Compare. Set Hash when those are used.
+type Problem[S any] struct {
+    Objective func(state S) float64
+    CopyState func(state S) S
+    Equal     func(a S, b S) bool
+    Hash      func(state S) uint64
+}
+
+type stateCost struct {
+    cost  float64
+    valid bool
+}
+
+// State is a state of a Problem. It satisfies ComparableSolution and
+// HashableSolution, and caches its cost until Modified is called, so that
+// operators only have to report whether they changed the state. States
+// should be copied with Copy: an assignment shares Value, and the cache
+// until Modified is called on one of the copies.
+type State[S any] struct {
+    Problem *Problem[S]
+    Value   S
+    cache   *stateCost
+}
+
+// NewState wraps value into a State of problem.
+func (problem *Problem[S]) NewState(value S) State[S] {
+    return State[S]{Problem: problem, Value: value, cache: &stateCost{}}
+}
+
+// Modified invalidates the cached cost. Operators changing Value must call
+// it, unless they are wrapped by the Operator functions below. The state
+// gets a cache of its own, so that copies made by assignment keep theirs.
+func (s *State[S]) Modified() {
+    s.cache = &stateCost{}
+}
+
+func (s State[S]) GetCost() float64 {
+    if s.cache == nil {
+        return s.Problem.Objective(s.Value)
+    }
+    if !s.cache.valid {
+        s.cache.cost = s.Problem.Objective(s.Value)
+        s.cache.valid = true
+    }
+    return s.cache.cost
+}
+
+func (s State[S]) Copy() State[S] {
+    result := State[S]{Problem: s.Problem, Value: s.Problem.CopyState(s.Value), cache: &stateCost{}}
+    if s.cache != nil {
+        *result.cache = *s.cache
+    }
+    return result
+}
+
+func (s State[S]) Compare(s2 State[S]) bool {
+    if s.Problem.Equal != nil {
+        return s.Problem.Equal(s.Value, s2.Value)
+    }
+    return reflect.DeepEqual(s.Value, s2.Value)
+}
+
+func (s State[S]) Hash() uint64 {
+    if s.Problem.Hash != nil {
+        return s.Problem.Hash(s.Value)
+    }
+    return 0
+}
+
+// Operator adapters
+//-------------------------------
+// The operators below change the state in place and return whether they
+// changed it. The adapters keep the cost up to date and report the cost
+// difference as the algorithms expect.
+
+// ImprovementOperator turns op into an improvement strategy. Changes that
+// do not improve the cost are reverted.
+func ImprovementOperator[S any](op func(state *S) bool) ImprovementStrategy[State[S]] {
+    return func(s *State[S]) float64 {
+        before := s.Copy()
+        cost := s.GetCost()
+
+        if !op(&s.Value) {
+            return 0.0
+        }
+        s.Modified()
+
+        costDiff := s.GetCost() - cost
+        if costDiff >= -ZERO {
+            *s = before
+            return 0.0
+        }
+
+        return costDiff
+    }
+}
+
+// DiversificationOperator turns op into a diversification strategy, which
+// keeps every change.
+func DiversificationOperator[S any](op func(state *S) bool) DiversificationStrategy[State[S]] {
+    return func(s *State[S]) float64 {
+        cost := s.GetCost()
+
+        if !op(&s.Value) {
+            return 0.0
+        }
+        s.Modified()
+
+        return s.GetCost() - cost
+    }
+}
+
+// CrossoverOperator turns op, which builds a child state from two parent
+// states, into a crossover strategy.
+func CrossoverOperator[S any](op func(father S, mother S) S) CrossoverStrategy[State[S]] {
+    return func(father State[S], mother State[S]) State[S] {
+        return father.Problem.NewState(op(father.Value, mother.Value))
+    }
+}
@@ -1,3 +1,3 @@
 @echo off
-rem go build -o build/train-maneuver.exe examples/train-maneuver.go
+go build -o build/train-maneuver.exe examples/train-maneuver.go
 go build -o build/a-shunt.exe examples/a-shunt.go
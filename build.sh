@@ -1,4 +1,4 @@
 #!/bin/bash
 
-#go build -o build/train-maneuver examples/train-maneuver.go
+go build -o build/train-maneuver examples/train-maneuver.go
 go build -o build/a-shunt examples/a-shunt.go
@@ -0,0 +1,221 @@
+package hx
+
+import (
+    "fmt"
+    "math"
+    "sort"
+)
+
+// Constraint handling
+//-------------------------------
+
+// ConstrainedSolution is implemented by solutions that can be infeasible.
+// GetViolation returns how much the constraints are violated, 0 meaning
+// the solution is feasible.
+type ConstrainedSolution interface {
+    GetViolation() float64
+}
+
+// ConstraintHandling selects how infeasible solutions are compared.
+type ConstraintHandling int
+
+const (
+    // DeathPenalty gives infeasible solutions an infinite cost, so that
+    // they are never accepted. The initial solution must be feasible.
+    DeathPenalty ConstraintHandling = iota
+    // StaticPenalty adds Weight times the violation to the cost.
+    StaticPenalty
+    // AdaptivePenalty is StaticPenalty with a Weight that grows while the
+    // search stays among infeasible solutions and shrinks while it stays
+    // among feasible ones.
+    AdaptivePenalty
+    // FeasibilityFirst prefers any feasible solution to any infeasible one,
+    // feasible ones by cost and infeasible ones by violation. Infeasible
+    // solutions cost InfeasibleOffset plus their violation.
+    FeasibilityFirst
+)
+
+// ConstraintHandler evaluates solutions under a ConstraintHandling, repairs
+// them and keeps the best feasible one found. Algorithms use it through
+// AlgState.Constraints.
+type ConstraintHandler[T any] struct {
+    Handling ConstraintHandling
+    Weight   float64
+    // InfeasibleOffset must be greater than the cost of any feasible
+    // solution, see FeasibilityFirst.
+    InfeasibleOffset float64
+
+    // Every AdaptEvery observed solutions, AdaptivePenalty multiplies Weight
+    // by WeightFactor when fewer than TargetFeasibility of them were
+    // feasible and divides it otherwise, within [MinWeight, MaxWeight].
+    AdaptEvery        int
+    TargetFeasibility float64
+    WeightFactor      float64
+    MinWeight         float64
+    MaxWeight         float64
+
+    // Repair, when set, is called on infeasible solutions created by
+    // perturbations and crossovers. It must keep the cost of s up to date.
+    Repair func(s *T)
+
+    BestFeasible     T
+    BestFeasibleCost float64
+    HasFeasible      bool
+    Observed         int
+    Feasible         int
+    Repairs          int
+}
+
+func Constraints[T any](handling ConstraintHandling) *ConstraintHandler[T] {
+    return &ConstraintHandler[T]{
+        Handling: handling,
+        Weight: 1.0,
+        InfeasibleOffset: 1e12,
+        AdaptEvery: 20,
+        TargetFeasibility: 0.5,
+        WeightFactor: 2.0,
+        MinWeight: 1e-3,
+        MaxWeight: 1e6,
+        BestFeasibleCost: math.Inf(1),
+    }
+}
+
+func getCost[T any](s T) float64 {
+    return any(s).(interface{ GetCost() float64 }).GetCost()
+}
+
+// GetViolation returns the violation of s, 0 when it is not a
+// ConstrainedSolution.
+func (c *ConstraintHandler[T]) GetViolation(s T) float64 {
+    if constrained, ok := any(s).(ConstrainedSolution); ok {
+        return constrained.GetViolation()
+    }
+    return 0.0
+}
+
+func (c *ConstraintHandler[T]) IsFeasible(s T) bool {
+    return c.GetViolation(s) <= ZERO
+}
+
+// GetFitness returns the cost of s under the constraint handling. Lower is
+// better.
+func (c *ConstraintHandler[T]) GetFitness(s T) float64 {
+    cost := getCost(s)
+    violation := c.GetViolation(s)
+
+    if violation <= ZERO {
+        return cost
+    }
+
+    switch c.Handling {
+    case DeathPenalty:
+        return math.Inf(1)
+    case FeasibilityFirst:
+        return c.InfeasibleOffset + violation
+    }
+
+    return cost + c.Weight*violation
+}
+
+// Handle repairs s if it is infeasible and Repair is set, then observes it.
+func (c *ConstraintHandler[T]) Handle(s *T) {
+    if c.Repair != nil && !c.IsFeasible(*s) {
+        c.Repair(s)
+        c.Repairs++
+    }
+    c.Observe(*s)
+}
+
+// Observe records s as a solution visited by the search: it updates the best
+// feasible solution and, with AdaptivePenalty, the penalty weight.
+func (c *ConstraintHandler[T]) Observe(s T) {
+    feasible := c.IsFeasible(s)
+
+    if feasible {
+        c.Feasible++
+        if cost := getCost(s); cost < c.BestFeasibleCost {
+            c.BestFeasible = any(s).(interface{ Copy() T }).Copy()
+            c.BestFeasibleCost = cost
+            c.HasFeasible = true
+        }
+    }
+    c.Observed++
+
+    if c.Handling == AdaptivePenalty && c.AdaptEvery > 0 && c.Observed % c.AdaptEvery == 0 {
+        ratio := float64(c.Feasible) / float64(c.AdaptEvery)
+        if ratio < c.TargetFeasibility {
+            c.Weight = math.Min(c.Weight*c.WeightFactor, c.MaxWeight)
+        } else {
+            c.Weight = math.Max(c.Weight/c.WeightFactor, c.MinWeight)
+        }
+        c.Feasible = 0
+    }
+}
+
+// Result returns best, or the best feasible solution found when best is
+// infeasible.
+func (c *ConstraintHandler[T]) Result(best T) T {
+    if c.HasFeasible && !c.IsFeasible(best) {
+        return c.BestFeasible
+    }
+    return best
+}
+
+// GetFitness returns the cost of s under the constraint handling, or its
+// plain cost when Constraints is nil.
+func (alg *AlgState[T]) GetFitness(s *T) float64 {
+    if alg.Constraints == nil {
+        return getCost(*s)
+    }
+    return alg.Constraints.GetFitness(*s)
+}
+
+// fitnessDiff returns a - b, 0 when both fitnesses are equal. Two infeasible
+// solutions under DeathPenalty are then equal rather than NaN apart.
+func fitnessDiff(a float64, b float64) float64 {
+    if a == b {
+        return 0.0
+    }
+    return a - b
+}
+
+// GetPenalty returns what the constraint handling adds to the cost of s.
+func (alg *AlgState[T]) GetPenalty(s *T) float64 {
+    return alg.GetFitness(s) - getCost(*s)
+}
+
+// SortByFitness sorts solutions from the lowest fitness to the highest.
+func (alg *AlgState[T]) SortByFitness(solutions []T) {
+    sort.SliceStable(solutions, func(i, j int) bool {
+        return alg.GetFitness(&solutions[i]) < alg.GetFitness(&solutions[j])
+    })
+}
+
+// HandleConstraints repairs and observes s when Constraints is set.
+func (alg *AlgState[T]) HandleConstraints(s *T) {
+    if alg.Constraints != nil {
+        alg.Constraints.Handle(s)
+    }
+}
+
+// ObserveConstraints observes s, without repairing it, when Constraints is
+// set. Algorithms call it on their initial solutions, so that a feasible one
+// is kept as the best feasible solution.
+func (alg *AlgState[T]) ObserveConstraints(s *T) {
+    if alg.Constraints != nil {
+        alg.Constraints.Observe(*s)
+    }
+}
+
+// LogFeasible logs the best feasible solution found when Constraints is set.
+func (alg *AlgState[T]) LogFeasible() {
+    if alg.Constraints == nil || !alg.Verbose {
+        return
+    }
+
+    if alg.Constraints.HasFeasible {
+        alg.LogCost("Best Feasible", alg.Constraints.BestFeasibleCost)
+    } else {
+        fmt.Printf("%-16s | None found\n", "Best Feasible")
+    }
+}
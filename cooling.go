@@ -0,0 +1,166 @@
+package hx
+
+import (
+    "math"
+)
+
+// Temperature calibration
+//-------------------------------
+
+// TemperatureCalibration reports the outcome of SAAlg.CalibrateTemperature.
+type TemperatureCalibration struct {
+    Samples            int     // moves sampled
+    PositiveDeltas     int     // sampled moves that worsened the cost
+    MeanDelta          float64 // mean of the positive deltas
+    InitialTemperature float64
+    FinalTemperature   float64
+}
+
+// GetTemperatureForAcceptance returns the temperature T at which the mean
+// of exp(-delta/T) over the given positive deltas equals probability. The
+// mean is increasing in T, so T is found by bisection on a log scale.
+func GetTemperatureForAcceptance(deltas []float64, probability float64) float64 {
+    acceptance := func(temperature float64) float64 {
+        sum := 0.0
+        for _, delta := range deltas {
+            sum += math.Exp(-delta/temperature)
+        }
+        return sum / float64(len(deltas))
+    }
+    
+    low := math.Log(ZERO)
+    high := math.Log(1e15)
+    
+    for i := 0; i < 100; i++ {
+        mid := (low + high) / 2
+        if acceptance(math.Exp(mid)) < probability {
+            low = mid
+        } else {
+            high = mid
+        }
+    }
+    
+    return math.Exp((low + high) / 2)
+}
+
+// Cooling schedules
+//-------------------------------
+
+// CoolingState describes the annealing at the end of a temperature step.
+type CoolingState struct {
+    Temperature        float64
+    InitialTemperature float64
+    Step               int // temperature steps completed, starting at 1
+    Proposed           int // moves proposed at the current temperature
+    Accepted           int // moves accepted at the current temperature
+}
+
+// GetAcceptanceRate returns the fraction of the moves proposed at the
+// current temperature that were accepted.
+func (state CoolingState) GetAcceptanceRate() float64 {
+    if state.Proposed == 0 {
+        return 0
+    }
+    return float64(state.Accepted) / float64(state.Proposed)
+}
+
+// CoolingSchedule decides the temperature of the next step of SAAlg.
+type CoolingSchedule interface {
+    NextTemperature(state CoolingState) float64
+}
+
+// GeometricCooling multiplies the temperature by 1-Rate at every step.
+type GeometricCooling struct {
+    Rate float64
+}
+
+func (c *GeometricCooling) NextTemperature(state CoolingState) float64 {
+    return state.Temperature * (1-c.Rate)
+}
+
+// LinearCooling subtracts Decrement from the temperature at every step.
+type LinearCooling struct {
+    Decrement float64
+}
+
+func (c *LinearCooling) NextTemperature(state CoolingState) float64 {
+    return state.Temperature - c.Decrement
+}
+
+// DefaultLogarithmicSteps is the number of steps after which
+// LogarithmicCooling ends the annealing when its MaxSteps is 0.
+const DefaultLogarithmicSteps = 10000
+
+// LogarithmicCooling sets the temperature of step k to T0/(1+ln(1+k)). The
+// next temperature is that of the step after the one of the current
+// temperature, so that reheating moves the schedule back. It cools too
+// slowly to ever reach a practical MinTemperature, so after MaxSteps steps
+// it sets the temperature to 0, which ends the annealing.
+type LogarithmicCooling struct {
+    MaxSteps int
+}
+
+func (c *LogarithmicCooling) NextTemperature(state CoolingState) float64 {
+    maxSteps := c.MaxSteps
+    if maxSteps <= 0 {
+        maxSteps = DefaultLogarithmicSteps
+    }
+    if state.Step >= maxSteps || state.Temperature <= 0 {
+        return 0
+    }
+    
+    // The current temperature is that of step k = exp(x)-1, with
+    // x = T0/T-1, and ln(1+(k+1)) = ln(1+exp(x))
+    x := state.InitialTemperature/state.Temperature - 1
+    next := x
+    if x < 30 {
+        next = math.Log(1 + math.Exp(x))
+    }
+    return state.InitialTemperature / (1 + next)
+}
+
+// LundyMeesCooling sets the next temperature to T/(1+Beta*T). It is meant
+// to be used with a single iteration per temperature.
+type LundyMeesCooling struct {
+    Beta float64
+}
+
+func (c *LundyMeesCooling) NextTemperature(state CoolingState) float64 {
+    return state.Temperature / (1 + c.Beta*state.Temperature)
+}
+
+// ExponentialPlateauCooling keeps the temperature constant for PlateauLength
+// steps and then multiplies it by exp(-Rate).
+type ExponentialPlateauCooling struct {
+    Rate          float64
+    PlateauLength int
+}
+
+func (c *ExponentialPlateauCooling) NextTemperature(state CoolingState) float64 {
+    if state.Step % Max(c.PlateauLength, 1) != 0 {
+        return state.Temperature
+    }
+    return state.Temperature * math.Exp(-c.Rate)
+}
+
+// DefaultTargetAcceptance is the acceptance rate AdaptiveCooling aims for
+// when its TargetAcceptance is not positive.
+const DefaultTargetAcceptance = 0.5
+
+// AdaptiveCooling cools geometrically at a pace driven by the acceptance
+// rate: faster while more than TargetAcceptance of the moves are accepted
+// and slower otherwise, down to a tenth of Rate.
+type AdaptiveCooling struct {
+    Rate             float64
+    TargetAcceptance float64
+}
+
+func (c *AdaptiveCooling) NextTemperature(state CoolingState) float64 {
+    target := c.TargetAcceptance
+    if target <= 0 {
+        target = DefaultTargetAcceptance
+    }
+    ratio := state.GetAcceptanceRate() / target
+    rate := c.Rate * math.Min(math.Max(ratio, 0.1), 2)
+    return state.Temperature * (1-rate)
+}
@@ -0,0 +1,325 @@
+package hx
+
+import (
+    "fmt"
+    "math"
+)
+
+// Elite pool
+//-------------------------------
+
+// ElitePool keeps up to Capacity good and diverse solutions. Distance
+// measures how different two solutions are, e.g. the number of moves
+// separating them.
+type ElitePool[T Solution[T]] struct {
+    Capacity int
+    Distance func(a T, b T) float64
+    // Fitness ranks the members, lower being better. When nil, they are
+    // ranked by cost. ILSAlg and PathRelinkingAlg set it to their own
+    // GetFitness when it is nil, so that a constraint handling applies.
+    Fitness func(s *T) float64
+    // MinDistance is the distance a candidate must keep from every member
+    // to be admitted, unless it is better than all of them.
+    MinDistance float64
+    Solutions   []T
+}
+
+func CreateElitePool[T Solution[T]](capacity int, distance func(a T, b T) float64) *ElitePool[T] {
+    return &ElitePool[T]{
+        Capacity: capacity,
+        Distance: distance,
+        MinDistance: 1,
+    }
+}
+
+func (pool *ElitePool[T]) getFitness(s T) float64 {
+    if pool.Fitness == nil {
+        return s.GetCost()
+    }
+    return pool.Fitness(&s)
+}
+
+func (pool *ElitePool[T]) Len() int {
+    return len(pool.Solutions)
+}
+
+// Add offers s to the pool and reports whether it was admitted. A candidate
+// better than every member is always admitted. Otherwise it must keep
+// MinDistance from every member and, when the pool is full, be better than
+// its worst member. It then replaces, among the members worse than it, the
+// one most similar to it.
+func (pool *ElitePool[T]) Add(s T) bool {
+    minDistance := math.Inf(1)
+    for _, elite := range pool.Solutions {
+        minDistance = math.Min(minDistance, pool.Distance(s, elite))
+    }
+
+    if len(pool.Solutions) > 0 && minDistance < ZERO {
+        return false
+    }
+
+    best, _ := pool.Best()
+    isBest := len(pool.Solutions) == 0 || fitnessDiff(pool.getFitness(best), pool.getFitness(s)) >= ZERO
+
+    if !isBest && minDistance < pool.MinDistance {
+        return false
+    }
+
+    if len(pool.Solutions) < pool.Capacity {
+        pool.Solutions = append(pool.Solutions, s.Copy())
+        return true
+    }
+
+    replaced := -1
+    replacedDistance := math.Inf(1)
+    for i, elite := range pool.Solutions {
+        if fitnessDiff(pool.getFitness(elite), pool.getFitness(s)) < ZERO {
+            continue
+        }
+        if d := pool.Distance(s, elite); d < replacedDistance {
+            replaced = i
+            replacedDistance = d
+        }
+    }
+
+    if replaced < 0 {
+        return false
+    }
+
+    pool.Solutions[replaced] = s.Copy()
+    return true
+}
+
+// Best returns the best member. It returns false when the pool is empty.
+func (pool *ElitePool[T]) Best() (T, bool) {
+    var best T
+    if len(pool.Solutions) == 0 {
+        return best, false
+    }
+
+    best = pool.Solutions[0]
+    for _, elite := range pool.Solutions[1:] {
+        if pool.getFitness(elite) < pool.getFitness(best) {
+            best = elite
+        }
+    }
+    return best, true
+}
+
+// Worst returns the worst member. It returns false when the pool is empty.
+func (pool *ElitePool[T]) Worst() (T, bool) {
+    var worst T
+    if len(pool.Solutions) == 0 {
+        return worst, false
+    }
+
+    worst = pool.Solutions[0]
+    for _, elite := range pool.Solutions[1:] {
+        if pool.getFitness(elite) > pool.getFitness(worst) {
+            worst = elite
+        }
+    }
+    return worst, true
+}
+
+// Random returns a random member. It returns false when the pool is empty.
+func (pool *ElitePool[T]) Random() (T, bool) {
+    var s T
+    if len(pool.Solutions) == 0 {
+        return s, false
+    }
+    return pool.Solutions[GetRandomInt(0, len(pool.Solutions)-1)], true
+}
+
+// Path relinking
+//-------------------------------
+
+// RelinkingMode selects the ends of the path explored between two
+// solutions.
+type RelinkingMode int
+
+const (
+    // ForwardRelinking walks from the worse solution to the better one.
+    ForwardRelinking RelinkingMode = iota
+    // BackwardRelinking walks from the better solution to the worse one,
+    // exploring the neighborhood of the better one more closely.
+    BackwardRelinking
+    // MixedRelinking walks from both ends at once, alternating steps,
+    // until the two paths meet.
+    MixedRelinking
+)
+
+// PathRelinkingAlg explores the solutions between two solutions by moving
+// one of them, step by step, toward the other. Every step makes the best
+// of the moves returned by RelinkingMoves. The improvement strategies, run
+// as a VNDAlg, polish intermediate solutions.
+type PathRelinkingAlg[T Solution[T]] struct {
+    AlgState[T]
+    Mode RelinkingMode
+    // VNDMode is the mode of the VNDAlg used for local search.
+    VNDMode VNDMode
+
+    // RelinkingMoves returns the moves that bring s one step closer to
+    // guide. It returns no move once s equals guide.
+    RelinkingMoves func(s *T, guide T) []Move[T]
+
+    // Truncation is the fraction of the path walked, 1 walking all of it.
+    // The length of the path is estimated by the number of moves from the
+    // initiating solution.
+    Truncation float64
+    // LocalSearchEvery runs the local search on every LocalSearchEvery-th
+    // intermediate solution. When 0, only the best intermediate solution
+    // is improved, after the walk.
+    LocalSearchEvery int
+    Relinks int
+}
+
+func PathRelinking[T Solution[T]](moves func(s *T, guide T) []Move[T]) PathRelinkingAlg[T] {
+    return PathRelinkingAlg[T]{
+        AlgState: CreateAlgState[T](),
+        Mode: BackwardRelinking,
+        RelinkingMoves: moves,
+        Truncation: 1.0,
+        LocalSearchEvery: 0,
+    }
+}
+
+// step makes the best move bringing s closer to guide. It returns false when
+// s already reached guide.
+func (pr *PathRelinkingAlg[T]) step(s *T, guide T) bool {
+    var best Move[T]
+    bestDelta := math.Inf(1)
+
+    for _, m := range pr.RelinkingMoves(s, guide) {
+        if delta := m.Delta(); best == nil || delta < bestDelta {
+            best = m
+            bestDelta = delta
+        }
+    }
+
+    if best == nil {
+        return false
+    }
+
+    best.Apply(s)
+    return true
+}
+
+// Relink explores the path between a and b and returns the best
+// intermediate solution found, after local search. It returns false when a
+// and b are neighbors, i.e. the path has no intermediate solution.
+func (pr *PathRelinkingAlg[T]) Relink(a T, b T) (T, bool) {
+    vnd := VND[T]()
+    vnd.Verbose = false
+    vnd.Mode = pr.VNDMode
+    vnd.Exploration = pr.Exploration
+    vnd.ExplorationK = pr.ExplorationK
+    vnd.Constraints = pr.Constraints
+    SetStrategiesEx(&vnd, pr.ImproveStrategiesEx)
+
+    better, worse := a, b
+    if pr.GetFitness(&b) < pr.GetFitness(&a) {
+        better, worse = b, a
+    }
+
+    from, to := worse.Copy(), better.Copy()
+    if pr.Mode != ForwardRelinking {
+        from, to = better.Copy(), worse.Copy()
+    }
+
+    length := len(pr.RelinkingMoves(&from, to))
+    maxSteps := int(math.Ceil(pr.Truncation*float64(length)))
+    if pr.Mode == MixedRelinking {
+        // Each end walks half of the path
+        maxSteps = int(math.Ceil(pr.Truncation*float64(length)/2))
+    }
+
+    var best T
+    found := false
+    steps := 0
+
+    consider := func(s *T, other T) {
+        // The last step reaches the other end, which is not intermediate
+        if len(pr.RelinkingMoves(s, other)) == 0 {
+            return
+        }
+
+        candidate := (*s).Copy()
+        if pr.LocalSearchEvery > 0 && steps % pr.LocalSearchEvery == 0 {
+            vnd.Improve(&candidate, candidate.GetCost())
+        }
+
+        if !found || pr.GetFitness(&candidate) < pr.GetFitness(&best) {
+            best = candidate
+            found = true
+        }
+    }
+
+    for steps < maxSteps {
+        if pr.Mode == MixedRelinking {
+            if !pr.step(&from, to) || !pr.step(&to, from) {
+                break
+            }
+            steps++
+            consider(&from, to)
+            consider(&to, from)
+        } else {
+            if !pr.step(&from, to) {
+                break
+            }
+            steps++
+            consider(&from, to)
+        }
+    }
+
+    if found && pr.LocalSearchEvery == 0 {
+        vnd.Improve(&best, best.GetCost())
+    }
+
+    pr.Relinks++
+    return best, found
+}
+
+// Improve relinks every pair of members of pool, offering the results to
+// the pool, and returns the best member. It is meant to run after another
+// algorithm filled the pool, as post-optimization. It returns the zero T
+// when the pool is empty.
+func (pr *PathRelinkingAlg[T]) Improve(pool *ElitePool[T]) T {
+    if pool.Fitness == nil {
+        pool.Fitness = pr.GetFitness
+    }
+
+    best, ok := pool.Best()
+    if !ok {
+        if pr.Verbose { fmt.Println("[PR EMPTY POOL]") }
+        return best
+    }
+
+    if pr.Verbose { fmt.Println("[PR STARTING]") }
+    pr.LogCost("Initial Solution", best.GetCost())
+
+    elites := make([]T, len(pool.Solutions))
+    copy(elites, pool.Solutions)
+
+    for i := 0; i < len(elites); i++ {
+        for j := i+1; j < len(elites); j++ {
+            s, ok := pr.Relink(elites[i], elites[j])
+            if !ok {
+                continue
+            }
+
+            pool.Add(s)
+            if pr.GetFitness(&s) < pr.GetFitness(&best) {
+                best = s
+                pr.Improvements++
+                pr.OnImprovement(&best, pr)
+                pr.LogCost(fmt.Sprintf("Improvement %-4d", pr.Improvements), best.GetCost())
+            }
+        }
+    }
+
+    pr.LogCost("Final Solution", best.GetCost())
+    if pr.Verbose { fmt.Println("[PR FINISHED]") }
+
+    return best
+}
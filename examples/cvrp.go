@@ -8,6 +8,8 @@ import (
     //"github.com/davecgh/go-spew/spew"
     "fmt"
     "github.com/nidoro/heuristix"
+    "encoding/binary"
+    "hash/fnv"
     
     "image/color"
     "gonum.org/v1/plot"
@@ -53,6 +55,19 @@ type Solution struct {
     NodeRoute [] int
 }
 
+// Tabu attributes of the moves: a node leaving (Out) or entering a route,
+// and an edge removed from a route.
+type RouteAttribute struct {
+    Node  int
+    Route int
+    Out   bool
+}
+
+type EdgeAttribute struct {
+    A int
+    B int
+}
+
 func MakeRoute(n int, vehicleCap int) *Route {
     route := new(Route)
     route.Order = make([] int, 1, n+1)
@@ -79,6 +94,20 @@ func Contains[T int](slice []T, item T) bool {
     return false
 }
 
+func Min(a, b int) int {
+    if a < b {
+        return a
+    }
+    return b
+}
+
+func Max(a, b int) int {
+    if a > b {
+        return a
+    }
+    return b
+}
+
 func Swap [T any] (a *T, b *T) {
     *a, *b = *b, *a
 }
@@ -275,6 +304,11 @@ func ImproveByReinsertingEx(s *Solution, heu hx.Heuristic[Solution]) float64 {
                             route2.Load += route2.Load + nodeB.Demand
                         }
                         sl.Cost = newCost
+                        // node b left route r1 and entered route r2
+                        hx.SetMoveAttributes(heu,
+                            hx.TabuAttribute{Key: RouteAttribute{Node: b, Route: r1, Out: true}},
+                            hx.TabuAttribute{Key: RouteAttribute{Node: b, Route: r2, Out: false}},
+                        )
                         if heu.AcceptSolution(&sl, newCost) {
                             *s = sl
                             return costDiff
@@ -333,6 +367,11 @@ func ImproveBy2OptEx(s *Solution, heu hx.Heuristic[Solution]) float64 {
                     }
                     
                     sl.Cost = newCost
+                    // edges (a, b) and (u, v) were removed
+                    hx.SetMoveAttributes(heu,
+                        hx.TabuAttribute{Key: EdgeAttribute{Min(a, b), Max(a, b)}},
+                        hx.TabuAttribute{Key: EdgeAttribute{Min(u, v), Max(u, v)}},
+                    )
                     if heu.AcceptSolution(&sl, newCost) {
                         *s = sl
                         return costDiff
@@ -570,21 +609,65 @@ func (s Solution) GetCost() float64 {
     return s.Cost
 }
 
+// getRouteKeys returns one key per route, its visiting order, sorted so
+// that the keys do not depend on the order of the routes.
+func (s Solution) getRouteKeys() []string {
+    keys := make([]string, len(s.Routes))
+    for r, route := range s.Routes {
+        keys[r] = fmt.Sprint(route.Order)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// Compare reports whether both solutions have the same routes, in any
+// order.
 func (s1 Solution) Compare(s2 Solution) bool {
-    if len(s1.Routes) == len(s2.Routes) && s1.Cost == s2.Cost {
-        for r, _ := range s1.Routes {
-            if len(s1.Routes[r].Order) == len(s2.Routes[r].Order) && s1.Routes[r].Load == s2.Routes[r].Load {
-                for i, _ := range s1.Routes {
-                    if (s1.Routes[r].Order[i] != s2.Routes[r].Order[i]) {
-                        return false
-                    }
-                }
-                return true
-            }
+    if len(s1.Routes) != len(s2.Routes) || s1.Cost != s2.Cost {
+        return false
+    }
+    
+    keys1 := s1.getRouteKeys()
+    keys2 := s2.getRouteKeys()
+    for r := range keys1 {
+        if keys1[r] != keys2[r] {
+            return false
         }
     }
     
-    return false
+    return true
+}
+
+// Hash sums the hashes of the routes, so that, like Compare, it does not
+// depend on the order of the routes.
+func (s Solution) Hash() uint64 {
+    b := make([]byte, 8)
+    sum := uint64(0)
+    
+    for _, route := range s.Routes {
+        h := fnv.New64a()
+        for _, nd := range route.Order {
+            binary.LittleEndian.PutUint64(b, uint64(nd))
+            h.Write(b)
+        }
+        sum += h.Sum64()
+    }
+    
+    return sum
+}
+
+func (s Solution) GetAttributes() []any {
+    attributes := make([]any, 0, s.Data.N+len(s.Routes))
+    
+    for _, route := range s.Routes {
+        for i := 0; i < len(route.Order)-1; i++ {
+            a := route.Order[i]
+            b := route.Order[i+1]
+            attributes = append(attributes, [2]int{Min(a, b), Max(a, b)})
+        }
+    }
+    
+    return attributes
 }
 
 func GetNodeSuccessor(s Solution, nodeId int) int {
@@ -690,6 +773,8 @@ func main() {
     //---------------------------------
     s0 = GenRandomSolution(&d)
     vnd := hx.VND[Solution]()
+    vnd.Mode = hx.PipeVND
+    vnd.Exploration = hx.BestImprovement
     vnd.AddImprovingStrategy(ImproveBySwapingAdjacent)
     vnd.AddImprovingStrategyEx(ImproveByReinsertingEx)
     vnd.AddImprovingStrategyEx(ImproveBy2OptEx)
@@ -700,8 +785,12 @@ func main() {
     //-----------------------
     saSolution := s0.Copy()
     sa := hx.SA[Solution]()
+    sa.CoolingSchedule = &hx.AdaptiveCooling{Rate: 0.001, TargetAcceptance: 0.3}
+    sa.ReheatAfter = 2000
+    sa.AutoTemperature = true
     sa.AddImprovingStrategyEx(ImproveBy2OptEx)
     sa.AddDiversificationStrategy(DiversifyByReinserting)
+    sa.AddAnnealingStrategyEx(ImproveByReinsertingEx)
     sa.Improve(&saSolution)
     fmt.Println()
     
@@ -710,6 +799,8 @@ func main() {
     ilsSolution := s0.Copy()
     ils := hx.ILS[Solution]()
     ils.MaxNonImprovingIter = 20
+    ils.Acceptance = &hx.LSMCAcceptance{Temperature: 5}
+    ils.Strength = hx.AdaptivePerturbation(1, 10)
     ils.AddImprovingStrategy(ImproveBySwapingAdjacent)
     ils.AddImprovingStrategyEx(ImproveByReinsertingEx)
     ils.AddImprovingStrategyEx(ImproveBy2OptEx)
@@ -724,6 +815,11 @@ func main() {
     ts := hx.TS[Solution]()
     ts.MaxNonImprovingIter = 100
     ts.TabuListMaxSize = 50
+    ts.Memory = hx.TabuByAttribute
+    ts.TenurePolicy = hx.ReactiveTabu(10, 5, 50)
+    ts.DefaultAspiration = true
+    ts.AddAspirationCriterion(hx.AspirationByObjective[Solution])
+    ts.MaxRestarts = 4
     ts.AddImprovingStrategyEx(ImproveByReinsertingEx)
     ts.AddImprovingStrategyEx(ImproveBy2OptEx)
     ts.Improve(&tsSolution)
@@ -746,6 +842,7 @@ func main() {
     
     ga := hx.GA[Solution]()
     ga.Elitism = 0.05
+    ga.RejectDuplicates = true
     ga.MaxNonImprovingIter = 200
     ga.AddCrossoverStrategy(CrossoverBRBAX)
     ga.AddMutationStrategy(DiversifyByReinserting)
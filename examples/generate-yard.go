@@ -0,0 +1,59 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "strconv"
+
+    "github.com/nidoro/heuristix/shunting"
+)
+
+// Usage: go run examples/generate-yard.go <output.json> [seed] [moves]
+func main() {
+    if len(os.Args) < 2 {
+        fmt.Println("usage: generate-yard <output.json> [seed] [moves]")
+        os.Exit(1)
+    }
+
+    generator := shunting.CreateGenerator()
+    generator.Sections = 8
+    generator.Loops = 1
+    generator.Wagons = 6
+    var err error
+    if len(os.Args) > 2 {
+        if generator.Seed, err = strconv.ParseInt(os.Args[2], 10, 64); err != nil {
+            fmt.Println("invalid seed:", err)
+            os.Exit(1)
+        }
+    }
+    if len(os.Args) > 3 {
+        if generator.Moves, err = strconv.Atoi(os.Args[3]); err != nil {
+            fmt.Println("invalid moves:", err)
+            os.Exit(1)
+        }
+    }
+
+    config, walk, err := generator.Generate()
+    if err != nil {
+        fmt.Println(err)
+        os.Exit(1)
+    }
+    if err := config.Save(os.Args[1]); err != nil {
+        fmt.Println(err)
+        os.Exit(1)
+    }
+    fmt.Printf("saved %s | walk: %d maneuvers, cost %g\n", os.Args[1], len(walk.Maneuvers), walk.Cost)
+
+    yard, err := shunting.CreateYard(config)
+    if err != nil {
+        fmt.Println(err)
+        os.Exit(1)
+    }
+    planner := shunting.CreatePlanner(yard)
+    plan, err := planner.Plan()
+    if err != nil {
+        fmt.Println(err)
+        os.Exit(1)
+    }
+    fmt.Printf("plan: %d maneuvers, cost %g, bound %g\n", len(plan.Maneuvers), plan.Cost, plan.Bound)
+}
@@ -1,8 +1,9 @@
 package main
 
 import (
-    "container/heap"
     "fmt"
+    "hash/fnv"
+    "github.com/nidoro/heuristix/search"
 )
 
 // State represents the current configuration of the pegs
@@ -10,57 +11,29 @@ type State struct {
     Pegs [][]int
 }
 
-// PriorityQueue implements a priority queue for the A* algorithm
-type PriorityQueue []*Item
-
-type Item struct {
-    state State
-    cost  int
-    index int
-}
-
-// Len returns the length of the priority queue
-func (pq PriorityQueue) Len() int {
-    return len(pq)
+// Hanoi is the tower of Hanoi as a search problem: moving all the disks
+// from the first peg to the last one
+type Hanoi struct {
+    TotalDisks int
 }
 
-// Less compares two items based on their cost
-func (pq PriorityQueue) Less(i, j int) bool {
-    return pq[i].cost < pq[j].cost
-}
-
-// Swap swaps two items in the priority queue
-func (pq PriorityQueue) Swap(i, j int) {
-    pq[i], pq[j] = pq[j], pq[i]
-    pq[i].index = i
-    pq[j].index = j
-}
-
-// Push adds an item to the priority queue
-func (pq *PriorityQueue) Push(x interface{}) {
-    n := len(*pq)
-    item := x.(*Item)
-    item.index = n
-    *pq = append(*pq, item)
-}
-
-// Pop removes the item with the lowest cost from the priority queue
-func (pq *PriorityQueue) Pop() interface{} {
-    old := *pq
-    n := len(old)
-    item := old[n-1]
-    *pq = old[0 : n-1]
-    return item
+// Start returns all the disks on the first peg
+func (h Hanoi) Start() State {
+    pegs := [][]int{{}, {}, {}}
+    for disk := h.TotalDisks; disk >= 1; disk-- {
+        pegs[0] = append(pegs[0], disk)
+    }
+    return State{Pegs: pegs}
 }
 
 // IsGoal checks if the current state is the goal state
-func (s State) IsGoal(totalDisks int) bool {
-    return len(s.Pegs[2]) == totalDisks
+func (h Hanoi) IsGoal(s State) bool {
+    return len(s.Pegs[2]) == h.TotalDisks
 }
 
-// GetNeighbors generates all possible next states
-func (s State) GetNeighbors() []State {
-    var neighbors []State
+// Successors generates all possible next states, each move costing 1
+func (h Hanoi) Successors(s State) []search.Successor[State] {
+    var neighbors []search.Successor[State]
     for i := 0; i < 3; i++ {
         if len(s.Pegs[i]) > 0 { // If peg i is not empty
             disk := s.Pegs[i][len(s.Pegs[i])-1] // Get the top disk
@@ -72,7 +45,7 @@ func (s State) GetNeighbors() []State {
                     }
                     newPegs[i] = newPegs[i][:len(newPegs[i])-1] // Remove disk from peg i
                     newPegs[j] = append(newPegs[j], disk) // Add disk to peg j
-                    neighbors = append(neighbors, State{Pegs: newPegs})
+                    neighbors = append(neighbors, search.Successor[State]{State: State{Pegs: newPegs}, Cost: 1})
                 }
             }
         }
@@ -81,56 +54,33 @@ func (s State) GetNeighbors() []State {
 }
 
 // Heuristic estimates the cost to reach the goal
-func Heuristic(s State, totalDisks int) int {
-    return totalDisks - len(s.Pegs[2]) // Disks not in the target peg
+func (h Hanoi) Heuristic(s State) float64 {
+    return float64(h.TotalDisks - len(s.Pegs[2])) // Disks not in the target peg
 }
 
-// AStar implements the A* algorithm
-func AStar(startState State, totalDisks int) bool {
-    openList := &PriorityQueue{}
-    heap.Init(openList)
-    heap.Push(openList, &Item{state: startState, cost: 0})
-
-    closedSet := make(map[string]struct{})
-
-    for openList.Len() > 0 {
-        currentItem := heap.Pop(openList).(*Item)
-        currentState := currentItem.state
-
-        if currentState.IsGoal(totalDisks) {
-            return true // Goal reached
-        }
-
-        // Create a unique key for the current state
-        key := fmt.Sprintf("%v", currentState.Pegs)
-        closedSet[key] = struct{}{}
-
-        for _, neighbor := range currentState.GetNeighbors() {
-            // Check if the neighbor is already evaluated
-            neighborKey := fmt.Sprintf("%v", neighbor.Pegs)
-            if _, found := closedSet[neighborKey]; found {
-                continue
-            }
-
-            gCost := currentItem.cost + 1
-            hCost := Heuristic(neighbor, totalDisks)
-            fCost := gCost + hCost
-
-            heap.Push(openList, &Item{state: neighbor, cost: fCost})
+// Hash identifies a state by its pegs
+func (h Hanoi) Hash(s State) uint64 {
+    hash := fnv.New64a()
+    for _, peg := range s.Pegs {
+        for _, disk := range peg {
+            hash.Write([]byte{byte(disk)})
         }
+        hash.Write([]byte{0xff}) // Peg separator
     }
-
-    return false // No solution found
+    return hash.Sum64()
 }
 
 func main() {
-    totalDisks := 3
-    initialPegs := [][]int{{3, 2, 1}, {}, {}} // Start with all disks on peg 0
-    startState := State{Pegs: initialPegs}
+    problem := Hanoi{TotalDisks: 3}
 
-    result := AStar(startState, totalDisks)
-    if result {
-        fmt.Println("Solution found!")
+    astar := search.AStar[State](problem)
+    result := astar.Search()
+
+    if result.Found {
+        fmt.Printf("Solution found! %d moves, %d nodes expanded\n", len(result.Path)-1, result.Expanded)
+        for _, s := range result.Path {
+            fmt.Println(s.Pegs)
+        }
     } else {
         fmt.Println("No solution found.")
     }
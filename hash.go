@@ -0,0 +1,147 @@
+package hx
+
+// Solution identity
+//-------------------------------
+
+// HashableSolution is implemented by solutions that can summarize themselves
+// in a 64-bit hash. Equal solutions must have equal hashes. When available,
+// the hash is used for constant-time lookups and Compare, if implemented, is
+// only called to resolve collisions.
+type HashableSolution interface {
+    Hash() uint64
+}
+
+// EqualSolutions reports whether a and b represent the same solution, using
+// the hashes to rule out different solutions quickly and Compare to confirm
+// a match. Solutions implementing neither interface are never equal.
+func EqualSolutions[T Solution[T]](a T, b T) bool {
+    ha, okA := any(a).(HashableSolution)
+    hb, okB := any(b).(HashableSolution)
+    if okA && okB && ha.Hash() != hb.Hash() {
+        return false
+    }
+
+    if ca, ok := any(a).(ComparableSolution[T]); ok {
+        return ca.Compare(b)
+    }
+
+    return okA && okB
+}
+
+type solutionMapItem[T Solution[T], V any] struct {
+    Solution T
+    Value    V
+}
+
+// SolutionMap associates values to solutions. Hashable solutions are kept
+// in buckets indexed by their hash; the others are kept in a list scanned
+// with Compare.
+type SolutionMap[T Solution[T], V any] struct {
+    hashed   map[uint64][]solutionMapItem[T, V]
+    unhashed []solutionMapItem[T, V]
+    size     int
+}
+
+func CreateSolutionMap[T Solution[T], V any]() SolutionMap[T, V] {
+    return SolutionMap[T, V]{
+        hashed: make(map[uint64][]solutionMapItem[T, V]),
+    }
+}
+
+func (m *SolutionMap[T, V]) bucket(s T) (uint64, []solutionMapItem[T, V], bool) {
+    if h, ok := any(s).(HashableSolution); ok {
+        key := h.Hash()
+        return key, m.hashed[key], true
+    }
+    return 0, m.unhashed, false
+}
+
+func (m *SolutionMap[T, V]) setBucket(key uint64, items []solutionMapItem[T, V], hashed bool) {
+    if !hashed {
+        m.unhashed = items
+    } else if len(items) == 0 {
+        delete(m.hashed, key)
+    } else {
+        m.hashed[key] = items
+    }
+}
+
+// Get returns the value associated to s, if any.
+func (m *SolutionMap[T, V]) Get(s T) (V, bool) {
+    _, items, _ := m.bucket(s)
+    for _, item := range items {
+        if EqualSolutions(item.Solution, s) {
+            return item.Value, true
+        }
+    }
+
+    var zero V
+    return zero, false
+}
+
+// Contains reports whether s is in the map.
+func (m *SolutionMap[T, V]) Contains(s T) bool {
+    _, ok := m.Get(s)
+    return ok
+}
+
+// Set associates value to s, replacing the value of an equal solution
+// already in the map. The map keeps s itself, not a copy.
+func (m *SolutionMap[T, V]) Set(s T, value V) {
+    if m.hashed == nil {
+        m.hashed = make(map[uint64][]solutionMapItem[T, V])
+    }
+
+    key, items, hashed := m.bucket(s)
+    for i := range items {
+        if EqualSolutions(items[i].Solution, s) {
+            items[i].Value = value
+            return
+        }
+    }
+
+    m.setBucket(key, append(items, solutionMapItem[T, V]{Solution: s, Value: value}), hashed)
+    m.size++
+}
+
+// Delete removes s from the map.
+func (m *SolutionMap[T, V]) Delete(s T) {
+    key, items, hashed := m.bucket(s)
+    for i := range items {
+        if EqualSolutions(items[i].Solution, s) {
+            items = append(items[:i], items[i+1:]...)
+            m.setBucket(key, items, hashed)
+            m.size--
+            return
+        }
+    }
+}
+
+// Len returns the number of solutions in the map.
+func (m *SolutionMap[T, V]) Len() int {
+    return m.size
+}
+
+// Range calls f for every solution in the map until f returns false.
+func (m *SolutionMap[T, V]) Range(f func(s T, value V) bool) {
+    for _, items := range m.hashed {
+        for _, item := range items {
+            if !f(item.Solution, item.Value) {
+                return
+            }
+        }
+    }
+
+    for _, item := range m.unhashed {
+        if !f(item.Solution, item.Value) {
+            return
+        }
+    }
+}
+
+// Clear removes every solution from the map.
+func (m *SolutionMap[T, V]) Clear() {
+    m.hashed = make(map[uint64][]solutionMapItem[T, V])
+    m.unhashed = nil
+    m.size = 0
+}
@@ -2,7 +2,6 @@ package hx
 
 import (
     "fmt"
-    "sort"
     "math"
     "math/rand"
 )
@@ -17,12 +16,44 @@ func GetRandomInt(min int, max int) int {
     return rand.Intn(max-min+1) + min
 }
 
+func Max(a, b int) int {
+    if a > b {
+        return a
+    }
+    return b
+}
+
+func Min(a, b int) int {
+    if a < b {
+        return a
+    }
+    return b
+}
+
 // AlgState struct and interface
 //--------------------------------
 type ImprovementStrategy[T any] func (s *T) float64
 type ImprovementStrategyEx[T any] func (s *T, heu Heuristic[T]) float64
 type ImprovementCallback[T any] func (s *T, heu Heuristic[T])
 
+// ExplorationMode selects how much of a neighborhood an Ex strategy scans
+// before a move is made. Strategies scanning through AcceptCost and
+// AcceptSolution can only keep candidates of a T with a Copy() T method:
+// for other types, best and k-th improvement make the first improving
+// move. Strategies made from a Neighborhood compare moves by Delta and
+// need no Copy.
+type ExplorationMode int
+
+const (
+    // FirstImprovement makes the first improving move found.
+    FirstImprovement ExplorationMode = iota
+    // BestImprovement scans the whole neighborhood and makes the best move.
+    BestImprovement
+    // KthImprovement scans until ExplorationK improving moves are found and
+    // makes the best of them.
+    KthImprovement
+)
+
 type AlgState[T any] struct {
     ImproveStrategiesEx []ImprovementStrategyEx[T]
     OnImprovement       ImprovementCallback[T]
@@ -33,12 +64,31 @@ type AlgState[T any] struct {
     NewCost float64
     BestCost float64
     Verbose bool
+    
+    MoveAttributes []TabuAttribute
+    
+    // Exploration is applied by Explore. Best and k-th improvement need
+    // T to have a Copy() T method; without one they fall back to first
+    // improvement.
+    Exploration ExplorationMode
+    ExplorationK int
+    Exploring bool
+    ExplorationStopped bool
+    Candidates int
+    BestCandidate T
+    BestCandidateCost float64
+    
+    // Constraints, when set, decides how infeasible solutions compare,
+    // see ConstraintHandler. Solutions are then compared by GetFitness.
+    Constraints *ConstraintHandler[T]
 }
 
 func CreateAlgState[T any]() AlgState[T] {
     return AlgState[T] {
         OnImprovement: func (s *T, heu Heuristic[T]) {},
         Verbose: true,
+        Exploration: FirstImprovement,
+        ExplorationK: 2,
     }
 }
 
@@ -63,17 +113,123 @@ func (alg AlgState[T]) LogCost(info string, cost float64) {
     }
 }
 
-func (alg AlgState[T]) AcceptSolution(s *T, cost float64) bool {
-    return true
+// AcceptSolution makes the move to s when exploring by first improvement.
+// Otherwise, it records s as a candidate and only lets the strategy make
+// the move when s is the best of the first ExplorationK candidates.
+func (alg *AlgState[T]) AcceptSolution(s *T, cost float64) bool {
+    if !alg.Exploring {
+        return true
+    }
+    
+    isBest := alg.Candidates == 0 || cost < alg.BestCandidateCost
+    if isBest {
+        alg.BestCandidate = *s
+        alg.BestCandidateCost = cost
+    }
+    
+    return alg.countCandidate(isBest)
 }
 
+// countCandidate counts an improving candidate, isBest telling whether it
+// is the best one so far. Under k-th improvement, the ExplorationK-th
+// candidate ends the scan, and the move is made when it is the best.
+func (alg *AlgState[T]) countCandidate(isBest bool) bool {
+    alg.Candidates++
+    if alg.Exploration != KthImprovement || alg.Candidates < alg.ExplorationK {
+        return false
+    }
+    
+    if isBest {
+        alg.Candidates = 0
+        alg.Exploring = false
+        return true
+    }
+    alg.ExplorationStopped = true
+    return false
+}
+
+// AcceptCost reports whether a candidate of cost newCost improves on the
+// current solution and, when exploring, on the best candidate so far. In
+// that case it returns the solution the strategy should build the
+// candidate on, a copy of s when exploring. Improving candidates that are
+// not the best still count toward ExplorationK.
 func (alg *AlgState[T]) AcceptCost(s *T, newCost float64) (bool, T) {
-    if newCost < alg.CurrentCost {
+    if alg.ExplorationStopped || newCost >= alg.CurrentCost {
+        return false, *s
+    }
+    
+    if !alg.Exploring {
         alg.NewCost = newCost
         return true, *s
-    } else {
+    }
+    
+    // Only candidates better than the best one so far are worth building
+    if alg.Candidates > 0 && newCost >= alg.BestCandidateCost {
+        alg.countCandidate(false)
         return false, *s
     }
+    
+    alg.NewCost = newCost
+    return true, any(*s).(interface{ Copy() T }).Copy()
+}
+
+// Explore runs strategy on s under the exploration mode. When the strategy
+// ends without making a move, the best candidate it proposed, if any, is
+// made by the framework. It returns the cost difference of the move.
+func (alg *AlgState[T]) Explore(strategy ImprovementStrategyEx[T], s *T) float64 {
+    return alg.explore(s, func() float64 { return strategy(s, alg) })
+}
+
+// exploreUnion is Explore with the neighborhoods of strategies taken as a
+// single one: candidates are compared across strategies, so that best
+// improvement makes the best move of all of them. Strategies run in order
+// until one makes a move or the exploration stops.
+func (alg *AlgState[T]) exploreUnion(strategies []ImprovementStrategyEx[T], s *T) float64 {
+    return alg.explore(s, func() float64 {
+        for i, strategy := range strategies {
+            alg.CurrentStrategy = i
+            if costDiff := strategy(s, alg); costDiff < 0.0 || alg.ExplorationStopped {
+                return costDiff
+            }
+        }
+        return 0.0
+    })
+}
+
+func (alg *AlgState[T]) explore(s *T, run func() float64) float64 {
+    _, copyable := any(*s).(interface{ Copy() T })
+    alg.Exploring = alg.Exploration != FirstImprovement && copyable
+    alg.ExplorationStopped = false
+    alg.Candidates = 0
+    
+    costDiff := run()
+    
+    if costDiff >= 0.0 && alg.Candidates > 0 {
+        *s = alg.BestCandidate
+        costDiff = alg.BestCandidateCost - alg.CurrentCost
+    }
+    
+    var zero T
+    alg.BestCandidate = zero
+    alg.Exploring = false
+    alg.ExplorationStopped = false
+    alg.Candidates = 0
+    
+    return costDiff
+}
+
+func (alg AlgState[T]) GetExplorationMode() ExplorationMode {
+    return alg.Exploration
+}
+
+func (alg AlgState[T]) GetExplorationK() int {
+    return alg.ExplorationK
+}
+
+// SetMoveAttributes declares the attributes of the move that is about to be
+// passed to AcceptSolution. Algorithms without attribute memory ignore them.
+func (alg *AlgState[T]) SetMoveAttributes(attributes ...TabuAttribute) {
+    alg.MoveAttributes = attributes
 }
 
 func (alg AlgState[T]) GetImprovementsCount() int {
@@ -86,14 +242,40 @@ func (alg AlgState[T]) GetCurrentStrategy() int {
 
 // VNDAlg struct
 //---------------
+
+// VNDMode selects how VNDAlg moves between neighborhoods.
+type VNDMode int
+
+const (
+    // BasicVND goes back to the first neighborhood after an improvement and
+    // to the next one otherwise. It stops when the last one fails.
+    BasicVND VNDMode = iota
+    // PipeVND stays in the improving neighborhood until it fails, then
+    // moves to the next one. It stops when the last one fails.
+    PipeVND
+    // CyclicVND moves to the next neighborhood after every attempt,
+    // wrapping around, and stops when all of them fail in a row.
+    CyclicVND
+    // RandomizedVND is BasicVND with the neighborhoods shuffled at the
+    // start of every pass.
+    RandomizedVND
+    // UnionVND treats the neighborhoods as a single one, scanned under the
+    // exploration mode: with best improvement, the best move of all of
+    // them is made. It stops when none of them improves. With first
+    // improvement, it makes the same moves as BasicVND.
+    UnionVND
+)
+
 type VNDAlg [T any] struct {
     AlgState[T]
+    Mode VNDMode
 }
 
 // Constructor
 func VND [T any] () VNDAlg[T] {
     return VNDAlg[T] {
         AlgState: CreateAlgState[T](),
+        Mode: BasicVND,
     }
 }
 
@@ -105,28 +287,89 @@ func SetStrategiesEx [T any] (vnd *VNDAlg[T], strategies []ImprovementStrategyEx
 func (vnd *VNDAlg[T]) Improve(s *T, cost float64) bool {
     if vnd.Verbose { fmt.Println("[VND STARTING]") }
     
-    stg := 0
     improved := false
     vnd.CurrentCost = cost
     vnd.BestCost = cost
     
     vnd.LogCost("Initial Solution", cost)
     
-    for stg < len(vnd.ImproveStrategiesEx) {
+    k := len(vnd.ImproveStrategiesEx)
+    order := make([]int, k)
+    for i := range order {
+        order[i] = i
+    }
+    
+    shuffle := func() {
+        if vnd.Mode == RandomizedVND {
+            rand.Shuffle(k, func(i, j int) { order[i], order[j] = order[j], order[i] })
+        }
+    }
+    shuffle()
+    
+    pos := 0
+    failures := 0
+    
+    for pos < k {
+        stg := order[pos]
         vnd.CurrentStrategy = stg
         strategy := vnd.ImproveStrategiesEx[stg]
-        costDiff := strategy(s, &vnd.AlgState)
         
-        if costDiff < 0.0 {
+        // Strategies only see costs, so a move that worsens the fitness
+        // under the constraint handling is undone
+        var before T
+        if vnd.Constraints != nil {
+            before = any(*s).(interface{ Copy() T }).Copy()
+        }
+        
+        var costDiff float64
+        if vnd.Mode == UnionVND {
+            costDiff = vnd.exploreUnion(vnd.ImproveStrategiesEx, s)
+        } else {
+            costDiff = vnd.Explore(strategy, s)
+        }
+        success := costDiff < 0.0
+        
+        if success && vnd.Constraints != nil && fitnessDiff(vnd.GetFitness(s), vnd.GetFitness(&before)) > -ZERO {
+            *s = before
+            success = false
+        }
+        
+        if success {
             improved = true
+            failures = 0
             vnd.CurrentCost += costDiff
             vnd.BestCost = vnd.CurrentCost
             vnd.Improvements += 1
-            stg = 0
+            if vnd.Constraints != nil {
+                vnd.Constraints.Observe(*s)
+            }
             vnd.OnImprovement(s, vnd)
             vnd.LogCost(fmt.Sprintf("Improvement %-4d", vnd.Improvements), vnd.BestCost)
         } else {
-            stg += 1
+            failures++
+        }
+        
+        switch vnd.Mode {
+        case PipeVND:
+            if !success {
+                pos++
+            }
+        case CyclicVND:
+            pos = (pos+1) % k
+            if failures >= k {
+                pos = k
+            }
+        case UnionVND:
+            if !success {
+                pos = k
+            }
+        default:
+            if success {
+                pos = 0
+                shuffle()
+            } else {
+                pos++
+            }
         }
     }
     
@@ -145,6 +388,36 @@ type Heuristic[T any] interface {
     GetCurrentStrategy() int
 }
 
+// AttributeHeuristic is implemented by heuristics that keep the tabu
+// attributes of the moves, see SetMoveAttributes.
+type AttributeHeuristic interface {
+    SetMoveAttributes(attributes ...TabuAttribute)
+}
+
+// SetMoveAttributes declares the attributes of the move that is about to be
+// passed to heu.AcceptSolution. It does nothing when heu does not keep
+// them.
+func SetMoveAttributes[T any](heu Heuristic[T], attributes ...TabuAttribute) {
+    if h, ok := heu.(AttributeHeuristic); ok {
+        h.SetMoveAttributes(attributes...)
+    }
+}
+
+// ExploringHeuristic is implemented by heuristics with an ExplorationMode.
+type ExploringHeuristic interface {
+    GetExplorationMode() ExplorationMode
+    GetExplorationK() int
+}
+
+// GetExploration returns the exploration mode of heu and its k, first
+// improvement when heu has none.
+func GetExploration[T any](heu Heuristic[T]) (ExplorationMode, int) {
+    if h, ok := heu.(ExploringHeuristic); ok {
+        return h.GetExplorationMode(), h.GetExplorationK()
+    }
+    return FirstImprovement, 1
+}
+
 // Heuristic
 //------------
 type Solution[T any] interface {
@@ -162,6 +435,13 @@ type DiversificationStrategy[T Solution[T]] func (s *T) float64
 type HeuristicBase[T Solution[T]] struct {
     AlgState[T]
     DiversificationStrategies [] DiversificationStrategy[T]
+    // VNDMode is the mode of the VNDAlg used for local search.
+    VNDMode VNDMode
+    // Selector chooses the diversification strategies to apply and learns
+    // which ones lead to improvements. When nil, they are chosen uniformly.
+    // What it learned carries over from one Improve call to the next; set a
+    // new selector to start afresh.
+    Selector OperatorSelector
 }
 
 type HeuristicInterface interface {
@@ -183,6 +463,24 @@ func CreateHeuristicBase[T Solution[T]]() HeuristicBase[T] {
 type ILSAlg [T Solution[T]] struct {
     HeuristicBase[T]
     MaxNonImprovingIter int
+    
+    // Acceptance decides whether each new local optimum replaces the
+    // current solution. When nil, only better ones do.
+    Acceptance AcceptanceCriterion
+    // Strength decides the number of random diversification moves of each
+    // perturbation. When nil, it is the number of iterations since the
+    // best solution last improved.
+    Strength PerturbationStrength
+    // RestartStrength is the number of random diversification moves
+    // applied to the current solution when Acceptance asks for a restart.
+    RestartStrength int
+    Restarts int
+    
+    // Pool, when set, is offered every local optimum found. Relinking,
+    // when set too, relinks its members after the search and the best
+    // solution it finds is kept if better.
+    Pool *ElitePool[T]
+    Relinking *PathRelinkingAlg[T]
 }
 
 // Constructor
@@ -190,43 +488,117 @@ func ILS [T Solution[T]]() ILSAlg[T] {
     return ILSAlg[T] {
         HeuristicBase: CreateHeuristicBase[T](),
         MaxNonImprovingIter: 5,
+        RestartStrength: 50,
     }
 }
 
 func (ils *ILSAlg[T]) Improve(s *T) {
     if ils.Verbose { fmt.Println("[ILS STARTING]") }
     ils.LogCost("Initial Solution", (*s).GetCost())
+    ils.ObserveConstraints(s)
+    if ils.Pool != nil && ils.Pool.Fitness == nil {
+        ils.Pool.Fitness = ils.GetFitness
+    }
     
     vnd := VND[T]()
     vnd.Verbose = false
+    vnd.Mode = ils.VNDMode
+    vnd.Exploration = ils.Exploration
+    vnd.ExplorationK = ils.ExplorationK
+    vnd.Constraints = ils.Constraints
     SetStrategiesEx(&vnd, ils.ImproveStrategiesEx)
     
+    acceptance := ils.Acceptance
+    if acceptance == nil {
+        acceptance = &BetterAcceptance{}
+    }
+    
+    // used are the strategies applied by the last perturbation
+    used := []int{}
+    perturb := func(moves int) {
+        used = used[:0]
+        for p := 0; p < moves; p++ {
+            m := ils.SelectOperator(len(ils.DiversificationStrategies))
+            ils.DiversificationStrategies[m](s)
+            used = append(used, m)
+        }
+        ils.HandleConstraints(s)
+    }
+    
     nonImprovingIter := 0
+    ils.Restarts = 0
     
     best := (*s).Copy()
+    current := (*s).Copy()
     
     for nonImprovingIter <= ils.MaxNonImprovingIter {
-        for p := 0; p < nonImprovingIter; p++ {
-            m := GetRandomInt(0, len(ils.DiversificationStrategies)-1)
-            ils.DiversificationStrategies[m](s)
+        if ils.Strength == nil {
+            perturb(nonImprovingIter)
+        } else {
+            perturb(ils.Strength.GetStrength())
         }
         
         vnd.Improve(s, (*s).GetCost())
         
-        if best.GetCost() - (*s).GetCost() >= ZERO {
+        if ils.Pool != nil {
+            ils.Pool.Add(*s)
+        }
+        
+        for _, m := range used {
+            ils.CreditOperator(m, ils.GetFitness(&current), ils.GetFitness(s))
+        }
+        
+        outcome := PerturbationOutcome{
+            Escaped: math.Abs(fitnessDiff(ils.GetFitness(&current), ils.GetFitness(s))) >= ZERO,
+        }
+        
+        if fitnessDiff(ils.GetFitness(&best), ils.GetFitness(s)) >= ZERO {
+            outcome.Improved = true
             ils.Improvements++
             best = (*s).Copy()
             ils.BestCost = best.GetCost()
             nonImprovingIter = 1
             ils.OnImprovement(s, ils)
-            vnd.LogCost(fmt.Sprintf("Improvement %-4d", vnd.Improvements), vnd.BestCost)
+            ils.LogCost(fmt.Sprintf("Improvement %-4d", ils.Improvements), ils.BestCost)
         } else {
-            *s = best.Copy()
             nonImprovingIter++
         }
+        
+        switch acceptance.Accept(ils.GetFitness(&current), ils.GetFitness(s), ils.GetFitness(&best), nonImprovingIter) {
+        case Accept:
+            outcome.Accepted = true
+            current = (*s).Copy()
+        case Reject:
+            *s = current.Copy()
+        case Restart:
+            ils.Restarts++
+            perturb(ils.RestartStrength)
+            vnd.Improve(s, (*s).GetCost())
+            current = (*s).Copy()
+            ils.LogCost(fmt.Sprintf("Restart %-8d", ils.Restarts), current.GetCost())
+        }
+        
+        if ils.Strength != nil {
+            ils.Strength.Update(outcome)
+        }
     }
     
+    if ils.Pool != nil && ils.Relinking != nil && ils.Pool.Len() > 1 {
+        relinked := ils.Relinking.Improve(ils.Pool)
+        if fitnessDiff(ils.GetFitness(&best), ils.GetFitness(&relinked)) >= ZERO {
+            best = relinked.Copy()
+            ils.BestCost = best.GetCost()
+            ils.LogCost("Path Relinking", ils.BestCost)
+        }
+    }
+    
+    *s = best
+    if ils.Constraints != nil {
+        *s = ils.Constraints.Result(best)
+    }
     ils.LogCost("Final Solution", (*s).GetCost())
+    ils.LogFeasible()
+    ils.LogWeights()
     if ils.Verbose { fmt.Println("[FINISHED ILS]") }
 }
 
@@ -238,6 +610,49 @@ type SAAlg[T Solution[T]] struct {
     InitialTemperature float64
     MinTemperature float64
     CoolingRate float64
+    
+    // CoolingSchedule decides the temperature of each step. When nil,
+    // the temperature is multiplied by 1-CoolingRate at every step.
+    CoolingSchedule CoolingSchedule
+    // MaxSteps limits the number of temperature steps; 0 means no limit.
+    MaxSteps int
+    
+    // The temperature is multiplied by ReheatFactor, without exceeding
+    // InitialTemperature, after ReheatAfter steps without improving the
+    // best solution, at most MaxReheats times. ReheatAfter 0 disables it.
+    ReheatAfter int
+    ReheatFactor float64
+    MaxReheats int
+    Reheats int
+    
+    // AutoTemperature replaces InitialTemperature and MinTemperature, before
+    // the run, by temperatures at which a typical worsening move is accepted
+    // with probability InitialAcceptance and FinalAcceptance respectively.
+    // See CalibrateTemperature.
+    AutoTemperature bool
+    CalibrationSamples int
+    InitialAcceptance float64
+    FinalAcceptance float64
+    Calibration TemperatureCalibration
+    
+    // AnnealingStrategiesEx are neighborhood scanning strategies chosen
+    // at random alongside DiversificationStrategies. They evaluate moves
+    // by delta and apply them in place once AcceptCost, which implements
+    // the Metropolis criterion at Temperature, accepts one. Each strategy
+    // is run twice: once to count its neighbors, and once more for
+    // AcceptCost to test only one of them, drawn at random.
+    AnnealingStrategiesEx []ImprovementStrategyEx[T]
+    // AnnealingNeighborhoods are chosen at random alongside them too. A
+    // random move is sampled and made if accepted, without any copy.
+    AnnealingNeighborhoods []Neighborhood[T]
+    Temperature float64
+    Accepted int
+
+    // neighbors counts the calls to AcceptCost during a scan, and
+    // sampledNeighbor is the one tested, -1 while counting
+    neighbors       int
+    sampledNeighbor int
+    applied         bool
 }
 
 func SA[T Solution[T]]() SAAlg[T] {
@@ -247,46 +662,244 @@ func SA[T Solution[T]]() SAAlg[T] {
         InitialTemperature: 1000,
         MinTemperature: 0.001,
         CoolingRate: 0.001,
+        ReheatAfter: 0,
+        ReheatFactor: 10,
+        MaxReheats: 5,
+        AutoTemperature: false,
+        CalibrationSamples: 100,
+        InitialAcceptance: 0.8,
+        FinalAcceptance: 0.001,
+    }
+}
+
+func (sa *SAAlg[T]) AddAnnealingStrategyEx(strategy ImprovementStrategyEx[T]) {
+    sa.AnnealingStrategiesEx = append(sa.AnnealingStrategiesEx, strategy)
+}
+
+func (sa *SAAlg[T]) AddAnnealingNeighborhood(n Neighborhood[T]) {
+    sa.AnnealingNeighborhoods = append(sa.AnnealingNeighborhoods, n)
+}
+
+// AcceptCost rejects every neighbor of the scan but the sampled one, which
+// it accepts with the Metropolis probability at the current temperature.
+// With Constraints, the sampled neighbor is accepted here, and the
+// Metropolis test is made on the fitness once the move is applied, see
+// acceptFitness. The returned solution is s itself, not a copy.
+func (sa *SAAlg[T]) AcceptCost(s *T, newCost float64) (bool, T) {
+    index := sa.neighbors
+    sa.neighbors++
+    if index != sa.sampledNeighbor {
+        return false, *s
+    }
+
+    costDiff := newCost - sa.CurrentCost
+    
+    if sa.Constraints != nil || costDiff < 0.0 || rand.Float64() < math.Exp(-costDiff/sa.Temperature) {
+        sa.NewCost = newCost
+        return true, *s
+    }
+    
+    return false, *s
+}
+
+// acceptFitness makes the Metropolis test on the fitness difference, cost
+// and penalty together, of a move to s applied with Constraints set.
+func (sa *SAAlg[T]) acceptFitness(fitnessBefore float64, s *T) bool {
+    if MetropolisDecision(fitnessDiff(sa.GetFitness(s), fitnessBefore), sa.Temperature) == Reject {
+        return false
+    }
+    sa.Constraints.Observe(*s)
+    return true
+}
+
+func (sa *SAAlg[T]) AcceptSolution(s *T, cost float64) bool {
+    sa.applied = true
+    return true
+}
+
+// CalibrateTemperature applies CalibrationSamples random diversification
+// moves to copies of s and, from the positive cost deltas observed, sets
+// InitialTemperature and MinTemperature so that the mean acceptance
+// probability of those deltas is InitialAcceptance and FinalAcceptance.
+func (sa *SAAlg[T]) CalibrateTemperature(s *T) {
+    if len(sa.DiversificationStrategies) == 0 {
+        if sa.Verbose { fmt.Println("Calibration      | No diversification strategy, keeping temperatures") }
+        return
+    }
+    
+    deltas := make([]float64, 0, sa.CalibrationSamples)
+    
+    for i := 0; i < sa.CalibrationSamples; i++ {
+        candidate := (*s).Copy()
+        m := GetRandomInt(0, len(sa.DiversificationStrategies)-1)
+        costDiff := sa.DiversificationStrategies[m](&candidate)
+        if costDiff > ZERO {
+            deltas = append(deltas, costDiff)
+        }
+    }
+    
+    sa.Calibration = TemperatureCalibration{
+        Samples: sa.CalibrationSamples,
+        PositiveDeltas: len(deltas),
+    }
+    
+    if len(deltas) == 0 {
+        if sa.Verbose { fmt.Println("Calibration      | No worsening move sampled, keeping temperatures") }
+        return
+    }
+    
+    for _, delta := range deltas {
+        sa.Calibration.MeanDelta += delta / float64(len(deltas))
+    }
+    
+    sa.InitialTemperature = GetTemperatureForAcceptance(deltas, sa.InitialAcceptance)
+    sa.MinTemperature = GetTemperatureForAcceptance(deltas, sa.FinalAcceptance)
+    sa.Calibration.InitialTemperature = sa.InitialTemperature
+    sa.Calibration.FinalTemperature = sa.MinTemperature
+    
+    if sa.Verbose {
+        fmt.Printf("%-16s | Mean delta: %-12.4f | Initial temperature: %-12.4f | Final temperature: %-12.4f\n",
+            "Calibration", sa.Calibration.MeanDelta, sa.InitialTemperature, sa.MinTemperature)
     }
 }
 
 func (sa *SAAlg[T]) Improve(s *T) {
     if sa.Verbose { fmt.Println("[SA STARTING]") }
     sa.LogCost("Initial Solution", (*s).GetCost())
+    sa.ObserveConstraints(s)
+    
+    if sa.AutoTemperature {
+        sa.CalibrateTemperature(s)
+    }
     
-    temperature := sa.InitialTemperature
+    schedule := sa.CoolingSchedule
+    if schedule == nil {
+        schedule = &GeometricCooling{Rate: sa.CoolingRate}
+    }
+    
+    sa.Temperature = sa.InitialTemperature
     best := (*s).Copy()
+    step := 0
+    nonImprovingSteps := 0
+    sa.Reheats = 0
 
-    for temperature > sa.MinTemperature {
+    for sa.Temperature > sa.MinTemperature && (sa.MaxSteps <= 0 || step < sa.MaxSteps) {
+        sa.Accepted = 0
+        improved := false
+        
         for i := 0; i < sa.IterationsEachTemperature; i++ {
-            candidate := (*s).Copy()
-            
-            sa.CurrentStrategy = GetRandomInt(0, len(sa.DiversificationStrategies)-1)
-            costDiff := sa.DiversificationStrategies[sa.CurrentStrategy](&candidate)
+            divCount := len(sa.DiversificationStrategies)
+            exCount := len(sa.AnnealingStrategiesEx)
+            sa.CurrentStrategy = sa.SelectOperator(divCount+exCount+len(sa.AnnealingNeighborhoods))
+            before := sa.GetFitness(s)
             
-            if costDiff < 0.0 || rand.Float64() < math.Exp(-costDiff/temperature) {
-                *s = candidate
+            if sa.CurrentStrategy < divCount {
+                candidate := (*s).Copy()
+                costDiff := sa.DiversificationStrategies[sa.CurrentStrategy](&candidate)
+                if sa.Constraints != nil {
+                    sa.Constraints.Handle(&candidate)
+                    costDiff = fitnessDiff(sa.GetFitness(&candidate), sa.GetFitness(s))
+                }
+                
+                if costDiff < 0.0 || rand.Float64() < math.Exp(-costDiff/sa.Temperature) {
+                    *s = candidate
+                    sa.Accepted++
+                }
+            } else if sa.CurrentStrategy < divCount+exCount {
+                // The strategy asks AcceptCost about its neighbors in a
+                // fixed order: a first scan counts them, and AcceptCost
+                // only tests a random one during the second
+                sa.CurrentCost = (*s).GetCost()
+                strategy := sa.AnnealingStrategiesEx[sa.CurrentStrategy-divCount]
+                sa.neighbors = 0
+                sa.sampledNeighbor = -1
+                strategy(s, sa)
+                
+                if sa.neighbors > 0 {
+                    sa.sampledNeighbor = rand.Intn(sa.neighbors)
+                    sa.neighbors = 0
+                    sa.applied = false
+                    var previous T
+                    if sa.Constraints != nil {
+                        previous = (*s).Copy()
+                    }
+                    strategy(s, sa)
+                    
+                    if sa.applied && sa.Constraints != nil && !sa.acceptFitness(before, s) {
+                        *s = previous
+                    } else if sa.applied {
+                        sa.Accepted++
+                    }
+                }
+            } else {
+                n := sa.AnnealingNeighborhoods[sa.CurrentStrategy-divCount-exCount]
+                m, ok := n.Sample(s)
+                if ok && sa.Constraints != nil {
+                    m.Apply(s)
+                    if sa.acceptFitness(before, s) {
+                        sa.Accepted++
+                    } else {
+                        m.Undo(s)
+                    }
+                } else if ok && MetropolisDecision(m.Delta(), sa.Temperature) == Accept {
+                    m.Apply(s)
+                    sa.Accepted++
+                }
             }
             
-            if ((*s).GetCost() < best.GetCost()) {
+            sa.CreditOperator(sa.CurrentStrategy, before, sa.GetFitness(s))
+            
+            if sa.GetFitness(s) < sa.GetFitness(&best) {
                 sa.Improvements++
-                best = *s
+                best = (*s).Copy()
+                improved = true
                 sa.BestCost = best.GetCost()
                 sa.OnImprovement(s, sa)
                 sa.LogCost(fmt.Sprintf("Improvement %-4d", sa.Improvements), sa.BestCost)
             }
         }
         
-        temperature *= (1-sa.CoolingRate)
+        step++
+        sa.Temperature = schedule.NextTemperature(CoolingState{
+            Temperature: sa.Temperature,
+            InitialTemperature: sa.InitialTemperature,
+            Step: step,
+            Proposed: sa.IterationsEachTemperature,
+            Accepted: sa.Accepted,
+        })
+        
+        if improved {
+            nonImprovingSteps = 0
+        } else {
+            nonImprovingSteps++
+        }
+        
+        if sa.ReheatAfter > 0 && nonImprovingSteps >= sa.ReheatAfter && sa.Reheats < sa.MaxReheats {
+            sa.Temperature = math.Min(sa.Temperature*sa.ReheatFactor, sa.InitialTemperature)
+            nonImprovingSteps = 0
+            sa.Reheats++
+            if sa.Verbose {
+                fmt.Printf("%-16s | Temperature: %-14.4f\n", fmt.Sprintf("Reheating %-4d", sa.Reheats), sa.Temperature)
+            }
+        }
     }
     
     *s = best
     
     vnd := VND[T]()
+    vnd.Mode = sa.VNDMode
+    vnd.Exploration = sa.Exploration
+    vnd.ExplorationK = sa.ExplorationK
+    vnd.Constraints = sa.Constraints
     SetStrategiesEx(&vnd, sa.ImproveStrategiesEx)
     vnd.Improve(s, (*s).GetCost())
     
+    if sa.Constraints != nil {
+        *s = sa.Constraints.Result(*s)
+    }
     sa.LogCost("Final Solution", (*s).GetCost())
+    sa.LogFeasible()
+    sa.LogWeights()
     if sa.Verbose { fmt.Println("[SA FINISHED]") }
 }
 
@@ -296,12 +909,49 @@ type TSAlg[T ComparableSolution[T]] struct {
     HeuristicBase[T]
     TabuListMaxSize     int
     MaxNonImprovingIter int
-    TabuList            [] ComparableSolution[T]
+    Memory              TabuMemory
+    TabuList            SolutionMap[T, TabuEntry]
+    TabuAttributes      map[any]TabuEntry
+    Iteration           int
+    
+    TenurePolicy        TenurePolicy
+    AspirationCriteria  [] AspirationCriterion[T]
+    DefaultAspiration   bool
+    
+    // Visited solutions are only recorded, see ReactivePolicy
+    CycleMemorySize     int
+    CyclesDetected      int
+    CycleLengthSum      int
+    Visited             SolutionMap[T, int]
+    VisitedSolutions    [] T
+    VisitedIterations   [] int
+    
+    MaxRestarts         int
+    EliteSize           int
+    EliteSolutions      [] T
+    Frequency           map[any]int
+    FrequencySamples    int
+    DiversificationWeight float64
+    DiversificationIter int
+    Phase               TabuPhase
     
     BestSolution        T
     CurrentSolution     T
     BestNeighbor        T
     BestNeighborCost    float64
+    BestNeighborAttributes []TabuAttribute
+    BestNeighborMove    Move[T]
+    
+    LeastTabuNeighbor   T
+    LeastTabuNeighborCost float64
+    LeastTabuNeighborAttributes []TabuAttribute
+    LeastTabuNeighborMove Move[T]
+    LeastTabuExpiration int
+    
+    // Neighborhoods are scanned after the strategies on every iteration.
+    // Their moves are made and undone to be evaluated, so no copy of the
+    // solution is made until a move is chosen.
+    Neighborhoods       [] Neighborhood[T]
 }
 
 func TS[T ComparableSolution[T]]() TSAlg[T] {
@@ -309,72 +959,412 @@ func TS[T ComparableSolution[T]]() TSAlg[T] {
         HeuristicBase: CreateHeuristicBase[T](),
         TabuListMaxSize: 20,
         MaxNonImprovingIter: 10,
+        Memory: TabuBySolution,
+        TabuList: CreateSolutionMap[T, TabuEntry](),
+        Visited: CreateSolutionMap[T, int](),
+        TabuAttributes: make(map[any]TabuEntry),
+        CycleMemorySize: 100,
+        MaxRestarts: 0,
+        EliteSize: 5,
+        Frequency: make(map[any]int),
+        DiversificationWeight: 1.0,
+        DiversificationIter: 10,
+        Phase: TabuSearchPhase,
     }
 }
 
+// AddNeighborhood adds a neighborhood scanned by the tabu search itself.
+func (ts *TSAlg[T]) AddNeighborhood(n Neighborhood[T]) {
+    ts.Neighborhoods = append(ts.Neighborhoods, n)
+}
+
+func (ts *TSAlg[T]) AddAspirationCriterion(criterion AspirationCriterion[T]) {
+    ts.AspirationCriteria = append(ts.AspirationCriteria, criterion)
+}
+
 func (ts *TSAlg[T]) AcceptCost(s *T, newCost float64) (bool, T) {
     return true, (*s).Copy()
 }
 
 func (ts *TSAlg[T]) AcceptSolution(sl *T, cost float64) bool {
-    isTabu := false
+    attributes := ts.MoveAttributes
+    ts.MoveAttributes = nil
+    ts.ConsiderNeighbor(sl, attributes, nil)
+    return false
+}
+
+// ConsiderNeighbor keeps sl as the best neighbor, or as the least tabu one,
+// when it is so. When m is not nil, sl is the current solution with m
+// applied and m is kept instead of a copy of sl.
+func (ts *TSAlg[T]) ConsiderNeighbor(sl *T, attributes []TabuAttribute, m Move[T]) {
+    status := ts.GetTabuStatus(sl, attributes)
     
-    for _, s2 := range ts.TabuList {
-        if s2.Compare(*sl) {
-            isTabu = true
-            break
+    if status.Tabu {
+        for _, criterion := range ts.AspirationCriteria {
+            if criterion(ts, sl, status) {
+                status.Tabu = false
+                break
+            }
         }
     }
     
-    if !isTabu && (*sl).GetCost() < ts.BestNeighborCost {
-        ts.BestNeighbor = (*sl).Copy()
-        ts.BestNeighborCost = (*sl).GetCost()
+    if status.Tabu {
+        if ts.DefaultAspiration && (status.Expiration < ts.LeastTabuExpiration ||
+            (status.Expiration == ts.LeastTabuExpiration && ts.GetFitness(sl) < ts.LeastTabuNeighborCost)) {
+            if m == nil {
+                ts.LeastTabuNeighbor = (*sl).Copy()
+            }
+            ts.LeastTabuNeighborMove = m
+            ts.LeastTabuNeighborCost = ts.GetFitness(sl)
+            ts.LeastTabuNeighborAttributes = attributes
+            ts.LeastTabuExpiration = status.Expiration
+        }
+    } else if cost := ts.GetPenalizedCost(sl); cost < ts.BestNeighborCost {
+        if m == nil {
+            ts.BestNeighbor = (*sl).Copy()
+        }
+        ts.BestNeighborMove = m
+        ts.BestNeighborCost = cost
+        ts.BestNeighborAttributes = attributes
     }
+}
+
+// IsTabu reports whether the neighbor sl, reached by a move with the given
+// attributes, is forbidden by the short-term memory.
+func (ts *TSAlg[T]) IsTabu(sl *T, attributes []TabuAttribute) bool {
+    return ts.GetTabuStatus(sl, attributes).Tabu
+}
+
+// GetTabuStatus looks up the memory entries matching the neighbor sl, reached
+// by a move with the given attributes. Expired attribute entries are dropped
+// on the way.
+func (ts *TSAlg[T]) GetTabuStatus(sl *T, attributes []TabuAttribute) TabuStatus {
+    status := TabuStatus{Improving: true}
     
-    return false
+    match := func(entry TabuEntry) {
+        status.Tabu = true
+        status.Improving = status.Improving && entry.Improving
+        if entry.Expiration > status.Expiration {
+            status.Expiration = entry.Expiration
+        }
+    }
+    
+    if ts.Memory == TabuByAttribute {
+        for _, attr := range attributes {
+            entry, ok := ts.TabuAttributes[attr.Key]
+            if !ok {
+                continue
+            }
+            if entry.Expiration > ts.Iteration {
+                match(entry)
+            } else {
+                delete(ts.TabuAttributes, attr.Key)
+            }
+        }
+    } else {
+        if entry, ok := ts.TabuList.Get(*sl); ok {
+            match(entry)
+        }
+    }
+    
+    if !status.Tabu {
+        status.Improving = false
+    }
+    
+    return status
+}
+
+// GetTenure returns the tenure for an entry recorded in the current iteration.
+func (ts *TSAlg[T]) GetTenure() int {
+    if ts.TenurePolicy == nil {
+        return ts.TabuListMaxSize
+    }
+    return ts.TenurePolicy.GetTenure()
+}
+
+// MakeTabu records the move that led to s in the short-term memory.
+// improving tells whether that move decreased the current cost.
+func (ts *TSAlg[T]) MakeTabu(s *T, attributes []TabuAttribute, improving bool) {
+    if ts.Memory == TabuByAttribute {
+        if ts.TabuAttributes == nil {
+            ts.TabuAttributes = make(map[any]TabuEntry)
+        }
+        for _, attr := range attributes {
+            tenure := attr.Tenure
+            if tenure <= 0 {
+                tenure = ts.GetTenure()
+            }
+            ts.TabuAttributes[attr.Key] = TabuEntry{
+                Expiration: ts.Iteration + tenure,
+                Improving: improving,
+            }
+        }
+        return
+    }
+    
+    expired := [] T{}
+    ts.TabuList.Range(func(s2 T, entry TabuEntry) bool {
+        if entry.Expiration <= ts.Iteration {
+            expired = append(expired, s2)
+        }
+        return true
+    })
+    for _, s2 := range expired {
+        ts.TabuList.Delete(s2)
+    }
+    
+    ts.TabuList.Set((*s).Copy(), TabuEntry{
+        Expiration: ts.Iteration + ts.GetTenure(),
+        Improving: improving,
+    })
+}
+
+// reactsToCycles reports whether TenurePolicy needs the cycles detected.
+func (ts *TSAlg[T]) reactsToCycles() bool {
+    policy, ok := ts.TenurePolicy.(ReactivePolicy)
+    return ok && policy.ReactsToCycles()
+}
+
+// DetectCycle reports whether s was already visited within the last
+// CycleMemorySize iterations and records it as visited.
+func (ts *TSAlg[T]) DetectCycle(s *T) bool {
+    cycle := false
+    
+    if iteration, ok := ts.Visited.Get(*s); ok {
+        cycle = true
+        ts.CyclesDetected++
+        ts.CycleLengthSum += ts.Iteration - iteration
+    }
+    
+    visited := (*s).Copy()
+    ts.Visited.Set(visited, ts.Iteration)
+    ts.VisitedSolutions = append(ts.VisitedSolutions, visited)
+    ts.VisitedIterations = append(ts.VisitedIterations, ts.Iteration)
+    
+    if len(ts.VisitedSolutions) > ts.CycleMemorySize {
+        oldest := ts.VisitedSolutions[0]
+        // Only forget it if it was not visited again since
+        if iteration, _ := ts.Visited.Get(oldest); iteration == ts.VisitedIterations[0] {
+            ts.Visited.Delete(oldest)
+        }
+        ts.VisitedSolutions = ts.VisitedSolutions[1:]
+        ts.VisitedIterations = ts.VisitedIterations[1:]
+    }
+    
+    return cycle
+}
+
+// GetPenalizedCost returns the fitness of sl plus, during diversification, a
+// penalty proportional to how often its attributes appeared in the solutions
+// visited so far.
+func (ts *TSAlg[T]) GetPenalizedCost(sl *T) float64 {
+    cost := ts.GetFitness(sl)
+    
+    if ts.Phase != TabuDiversificationPhase || ts.FrequencySamples == 0 {
+        return cost
+    }
+    
+    attributed, ok := any(*sl).(AttributedSolution)
+    if !ok {
+        return cost
+    }
+    
+    penalty := 0.0
+    for _, attr := range attributed.GetAttributes() {
+        penalty += float64(ts.Frequency[attr])
+    }
+    
+    return cost + ts.DiversificationWeight*penalty/float64(ts.FrequencySamples)
+}
+
+// RecordFrequency adds the attributes of s to the long-term memory.
+func (ts *TSAlg[T]) RecordFrequency(s *T) {
+    attributed, ok := any(*s).(AttributedSolution)
+    if !ok {
+        return
+    }
+    
+    if ts.Frequency == nil {
+        ts.Frequency = make(map[any]int)
+    }
+    
+    for _, attr := range attributed.GetAttributes() {
+        ts.Frequency[attr]++
+    }
+    ts.FrequencySamples++
+}
+
+// RecordElite keeps s among the EliteSize best distinct solutions found.
+func (ts *TSAlg[T]) RecordElite(s *T) {
+    for _, elite := range ts.EliteSolutions {
+        if EqualSolutions(elite, *s) {
+            return
+        }
+    }
+    
+    ts.EliteSolutions = append(ts.EliteSolutions, (*s).Copy())
+    ts.SortByFitness(ts.EliteSolutions)
+    if len(ts.EliteSolutions) > ts.EliteSize {
+        ts.EliteSolutions = ts.EliteSolutions[:ts.EliteSize]
+    }
+}
+
+// ClearShortTermMemory forgets every tabu entry and visited solution.
+func (ts *TSAlg[T]) ClearShortTermMemory() {
+    ts.TabuList.Clear()
+    ts.TabuAttributes = make(map[any]TabuEntry)
+    ts.Visited.Clear()
+    ts.VisitedSolutions = nil
+    ts.VisitedIterations = nil
+}
+
+// Intensify restarts the search from one of the elite solutions.
+func (ts *TSAlg[T]) Intensify(s *T) {
+    ts.Phase = TabuIntensificationPhase
+    ts.ClearShortTermMemory()
+    
+    if len(ts.EliteSolutions) > 0 {
+        *s = ts.EliteSolutions[GetRandomInt(0, len(ts.EliteSolutions)-1)].Copy()
+    }
+    
+    ts.LogCost("Intensification", (*s).GetCost())
+}
+
+// Diversify makes the next DiversificationIter iterations penalize
+// frequently visited attributes.
+func (ts *TSAlg[T]) Diversify(s *T) {
+    ts.Phase = TabuDiversificationPhase
+    ts.ClearShortTermMemory()
+    ts.LogCost("Diversification", (*s).GetCost())
+}
+
+func (ts *TSAlg[T]) LogCycles() {
+    if !ts.Verbose || !ts.reactsToCycles() {
+        return
+    }
+    
+    meanLength := 0.0
+    if ts.CyclesDetected > 0 {
+        meanLength = float64(ts.CycleLengthSum) / float64(ts.CyclesDetected)
+    }
+    
+    fmt.Printf("%-16s | Count: %-6d | Mean length: %-8.2f | Tenure: %d\n", "Cycles", ts.CyclesDetected, meanLength, ts.GetTenure())
 }
 
 func (ts *TSAlg[T]) Improve(s *T) {
     if ts.Verbose { fmt.Println("[TS STARTING]") }
     ts.LogCost("Initial Solution", (*s).GetCost())
+    ts.ObserveConstraints(s)
     
     nonImprovingIter := 0
+    restarts := 0
+    phaseIter := 0
     
     ts.BestSolution = (*s).Copy()
+    ts.Phase = TabuSearchPhase
+    ts.RecordElite(s)
+    ts.RecordFrequency(s)
     
-    for nonImprovingIter < ts.MaxNonImprovingIter {
+    for {
+        if nonImprovingIter >= ts.MaxNonImprovingIter {
+            if restarts >= ts.MaxRestarts {
+                break
+            }
+            
+            restarts++
+            nonImprovingIter = 0
+            phaseIter = 0
+            
+            if restarts % 2 == 1 {
+                ts.Intensify(s)
+            } else {
+                ts.Diversify(s)
+            }
+        }
+        
+        if ts.Phase == TabuDiversificationPhase && phaseIter >= ts.DiversificationIter {
+            ts.Phase = TabuSearchPhase
+        }
+        
         ts.CurrentSolution = (*s).Copy()
         ts.BestNeighborCost = math.Inf(1)
+        ts.BestNeighborAttributes = nil
+        ts.BestNeighborMove = nil
+        ts.LeastTabuNeighborCost = math.Inf(1)
+        ts.LeastTabuNeighborAttributes = nil
+        ts.LeastTabuNeighborMove = nil
+        ts.LeastTabuExpiration = math.MaxInt
         
         for _, strategy := range ts.ImproveStrategiesEx {
             _ = strategy(s, ts)
         }
         
+        for _, n := range ts.Neighborhoods {
+            n.Moves(s, func(m Move[T]) bool {
+                m.Apply(s)
+                ts.ConsiderNeighbor(s, GetMoveAttributes(m), m)
+                m.Undo(s)
+                return true
+            })
+        }
+        
         if ts.BestNeighborCost == math.Inf(1) {
-            break
+            if ts.LeastTabuNeighborCost == math.Inf(1) {
+                nonImprovingIter = ts.MaxNonImprovingIter
+                continue
+            }
+            ts.BestNeighbor = ts.LeastTabuNeighbor
+            ts.BestNeighborMove = ts.LeastTabuNeighborMove
+            ts.BestNeighborCost = ts.LeastTabuNeighborCost
+            ts.BestNeighborAttributes = ts.LeastTabuNeighborAttributes
+        }
+        
+        if ts.BestNeighborMove != nil {
+            ts.BestNeighborMove.Apply(s)
+        } else {
+            *s = ts.BestNeighbor.Copy()
         }
+        ts.Iteration++
+        phaseIter++
         
-        *s = ts.BestNeighbor.Copy()
+        if ts.Constraints != nil {
+            ts.Constraints.Observe(*s)
+        }
         
-        if (ts.BestSolution.GetCost() - (*s).GetCost() >= ZERO) {
+        if (fitnessDiff(ts.GetFitness(&ts.BestSolution), ts.GetFitness(s)) >= ZERO) {
             ts.Improvements++
             ts.BestSolution = (*s).Copy()
             nonImprovingIter = 0
             ts.BestCost = ts.BestSolution.GetCost()
+            ts.RecordElite(s)
             ts.OnImprovement(s, ts)
             ts.LogCost(fmt.Sprintf("Improvement %-4d", ts.Improvements), ts.BestCost)
         } else {
             nonImprovingIter++
         }
         
-        ts.TabuList = append(ts.TabuList, *s)
-        if len(ts.TabuList) > ts.TabuListMaxSize {
-            ts.TabuList = ts.TabuList[1:]
+        ts.RecordFrequency(s)
+        
+        cycle := false
+        if ts.reactsToCycles() {
+            cycle = ts.DetectCycle(s)
+        }
+        if ts.TenurePolicy != nil {
+            ts.TenurePolicy.Update(cycle)
         }
+        
+        improving := ts.GetFitness(s) < ts.GetFitness(&ts.CurrentSolution)
+        ts.MakeTabu(s, ts.BestNeighborAttributes, improving)
     }
     
+    ts.Phase = TabuSearchPhase
     *s = ts.BestSolution
+    if ts.Constraints != nil {
+        *s = ts.Constraints.Result(ts.BestSolution)
+    }
     ts.LogCost("Final Solution", (*s).GetCost())
+    ts.LogFeasible()
+    ts.LogCycles()
     if ts.Verbose { fmt.Println("[TS FINISHED]") }
 }
 
@@ -390,6 +1380,12 @@ type GAAlg[T Solution[T]] struct {
     Elitism float64
     CrossoverProbability float64
     MutationProbability float64
+    
+    // RejectDuplicates breeds a child again, up to MaxDuplicateRetries
+    // times, when it equals a member of the generation being built.
+    // Solutions are told apart with Hash and Compare, see EqualSolutions.
+    RejectDuplicates bool
+    MaxDuplicateRetries int
 }
 
 func GA[T Solution[T]]() GAAlg[T] {
@@ -399,6 +1395,8 @@ func GA[T Solution[T]]() GAAlg[T] {
         TournamentSize: 2,
         CrossoverProbability: 0.65,
         MutationProbability: 0.1,
+        RejectDuplicates: false,
+        MaxDuplicateRetries: 10,
     }
 }
 
@@ -412,6 +1410,12 @@ func Contains[T int](slice []T, item T) bool {
 }
 
 func SelectParents[T Solution[T]](population []T, numParents int, tournamentSize int) []T {
+    return SelectParentsBy(population, numParents, tournamentSize, func(s *T) float64 { return (*s).GetCost() })
+}
+
+// SelectParentsBy runs the tournaments of SelectParents comparing the
+// candidates by fitness.
+func SelectParentsBy[T Solution[T]](population []T, numParents int, tournamentSize int, fitness func(s *T) float64) []T {
     parents := make([]T, numParents)
     
     candidates := make([]int, 0, numParents * tournamentSize)
@@ -430,8 +1434,8 @@ func SelectParents[T Solution[T]](population []T, numParents int, tournamentSize
         
         for k := 0; k < tournamentSize; k++ {
             candidate := population[candidates[j+k]]
-            if candidate.GetCost() < best.GetCost() {
-                best = population[candidates[j]]
+            if fitness(&candidate) < fitness(&best) {
+                best = candidate
             }
         }
         
@@ -455,10 +1459,47 @@ func (ga *GAAlg[T]) AddMutationStrategy(strategy DiversificationStrategy[T]) {
     ga.AddDiversificationStrategy(strategy)
 }
 
+// Breed creates a child from two distinct random parents.
+func (ga *GAAlg[T]) Breed(parents []T) T {
+    p1Index := GetRandomInt(0, len(parents)-1)
+    p2Index := GetRandomInt(0, len(parents)-1)
+    
+    for p1Index == p2Index {
+        p2Index = GetRandomInt(0, len(parents)-1)
+    }
+    
+    father := parents[p1Index]
+    mother := parents[p2Index]
+    
+    var child T
+    if rand.Float64() <= ga.CrossoverProbability {
+        crossover := ga.CrossoverStrategies[GetRandomInt(0, len(ga.CrossoverStrategies)-1)]
+        child = crossover(father, mother)
+    } else if rand.Float64() <= 0.5 {
+        child = father.Copy()
+    } else {
+        child = mother.Copy()
+    }
+    
+    if rand.Float64() <= ga.MutationProbability {
+        m := ga.SelectOperator(len(ga.DiversificationStrategies))
+        before := ga.GetFitness(&child)
+        ga.DiversificationStrategies[m](&child)
+        ga.CreditOperator(m, before, ga.GetFitness(&child))
+    }
+    
+    ga.HandleConstraints(&child)
+    
+    return child
+}
+
 func (ga *GAAlg[T]) Improve(population []T) T {
     if ga.Verbose { fmt.Println("[GA STARTING]") }
     
-    sort.Sort(ByCost[T](population))
+    for i := range population {
+        ga.ObserveConstraints(&population[i])
+    }
+    ga.SortByFitness(population)
     best := population[0]
     ga.LogCost("Initial Solution", best.GetCost())
     
@@ -466,40 +1507,31 @@ func (ga *GAAlg[T]) Improve(population []T) T {
     eliteSize := int(ga.Elitism * float64(len(population)))
     
     for nonImprovingIter < ga.MaxNonImprovingIter {
-        parents := SelectParents(population, len(population)/2, ga.TournamentSize)
+        parents := SelectParentsBy(population, len(population)/2, ga.TournamentSize, ga.GetFitness)
         
-        for i := eliteSize; i < len(population); i++ {
-            p1Index := GetRandomInt(0, len(parents)-1)
-            p2Index := GetRandomInt(0, len(parents)-1)
-            
-            for p1Index == p2Index {
-                p2Index = GetRandomInt(0, len(parents)-1)
-            }
-            
-            father := parents[p1Index]
-            mother := parents[p2Index]
-            
-            var child T
-            if rand.Float64() <= ga.CrossoverProbability {
-                crossover := ga.CrossoverStrategies[GetRandomInt(0, len(ga.CrossoverStrategies)-1)]
-                child = crossover(father, mother)
-            } else if rand.Float64() <= 0.5 {
-                child = father.Copy()
-            } else {
-                child = mother.Copy()
+        generation := CreateSolutionMap[T, bool]()
+        if ga.RejectDuplicates {
+            for i := 0; i < eliteSize; i++ {
+                generation.Set(population[i], true)
             }
+        }
+        
+        for i := eliteSize; i < len(population); i++ {
+            child := ga.Breed(parents)
             
-            if rand.Float64() <= ga.MutationProbability {
-                strategy := ga.DiversificationStrategies[GetRandomInt(0, len(ga.DiversificationStrategies)-1)]
-                strategy(&child)
+            if ga.RejectDuplicates {
+                for retry := 0; retry < ga.MaxDuplicateRetries && generation.Contains(child); retry++ {
+                    child = ga.Breed(parents)
+                }
+                generation.Set(child, true)
             }
             
             population[i] = child
         }
         
-        sort.Sort(ByCost[T](population))
+        ga.SortByFitness(population)
         
-        if population[0].GetCost() < best.GetCost() {
+        if ga.GetFitness(&population[0]) < ga.GetFitness(&best) {
             best = population[0]
             nonImprovingIter = 0
             ga.Improvements++
@@ -510,7 +1542,12 @@ func (ga *GAAlg[T]) Improve(population []T) T {
         }
     }
     
+    if ga.Constraints != nil {
+        best = ga.Constraints.Result(best)
+    }
     ga.LogCost("Final Solution", best.GetCost())
+    ga.LogFeasible()
+    ga.LogWeights()
     if ga.Verbose { fmt.Println("[GA FINISHED]") }
     
     return best
@@ -0,0 +1,192 @@
+package hx
+
+import (
+    "math"
+    "testing"
+)
+
+type testSolution struct {
+    Id   int
+    Cost float64
+}
+
+func (s testSolution) GetCost() float64 {
+    return s.Cost
+}
+
+func (s testSolution) Copy() testSolution {
+    return s
+}
+
+func (s testSolution) Compare(s2 testSolution) bool {
+    return s.Id == s2.Id
+}
+
+// createFixedStrategy returns a strategy proposing one neighbor per cost,
+// in order, the neighbor of index i having id i+1. It records in seen the
+// ids of the neighbors AcceptCost let it build.
+func createFixedStrategy(costs []float64, seen *[]int) ImprovementStrategyEx[testSolution] {
+    return func(s *testSolution, heu Heuristic[testSolution]) float64 {
+        before := s.Cost
+        for i, cost := range costs {
+            ok, neighbor := heu.AcceptCost(s, cost)
+            if !ok {
+                continue
+            }
+            neighbor.Id = i+1
+            neighbor.Cost = cost
+            *seen = append(*seen, neighbor.Id)
+            if heu.AcceptSolution(&neighbor, cost) {
+                *s = neighbor
+                return cost - before
+            }
+        }
+        return 0.0
+    }
+}
+
+func TestExplore(t *testing.T) {
+    // Neighbors 1 to 6 of a solution of cost 10; 4 does not improve
+    costs := []float64{9, 5, 8, 12, 7, 3}
+
+    cases := []struct {
+        name   string
+        mode   ExplorationMode
+        k      int
+        result int
+        seen   []int
+    }{
+        {"first", FirstImprovement, 0, 1, []int{1}},
+        {"best", BestImprovement, 0, 6, []int{1, 2, 6}},
+        // The second candidate is the best so far, so it is made at once
+        {"2nd", KthImprovement, 2, 2, []int{1, 2}},
+        // The third candidate, 3, is not the best: the scan ends there and
+        // the best of the first three, 2, is made
+        {"3rd", KthImprovement, 3, 2, []int{1, 2}},
+        {"4th", KthImprovement, 4, 2, []int{1, 2}},
+        {"5th", KthImprovement, 5, 6, []int{1, 2, 6}},
+    }
+
+    for _, c := range cases {
+        alg := CreateAlgState[testSolution]()
+        alg.Exploration = c.mode
+        alg.ExplorationK = c.k
+        alg.CurrentCost = 10
+
+        seen := []int{}
+        s := testSolution{Cost: 10}
+        costDiff := alg.Explore(createFixedStrategy(costs, &seen), &s)
+
+        if s.Id != c.result {
+            t.Errorf("%s: neighbor %d made, expected %d", c.name, s.Id, c.result)
+        }
+        if costDiff != costs[c.result-1] - 10 {
+            t.Errorf("%s: cost difference %g, expected %g", c.name, costDiff, costs[c.result-1] - 10)
+        }
+        if len(seen) != len(c.seen) {
+            t.Errorf("%s: neighbors %v built, expected %v", c.name, seen, c.seen)
+            continue
+        }
+        for i := range seen {
+            if seen[i] != c.seen[i] {
+                t.Errorf("%s: neighbors %v built, expected %v", c.name, seen, c.seen)
+                break
+            }
+        }
+    }
+}
+
+// constrainedSolution is a testSolution violating its constraints by
+// Violation.
+type constrainedSolution struct {
+    testSolution
+    Violation float64
+}
+
+func (s constrainedSolution) GetViolation() float64 {
+    return s.Violation
+}
+
+func (s constrainedSolution) Copy() constrainedSolution {
+    return s
+}
+
+func (s constrainedSolution) Compare(s2 constrainedSolution) bool {
+    return s.Id == s2.Id
+}
+
+func TestDeathPenalty(t *testing.T) {
+    c := Constraints[constrainedSolution](DeathPenalty)
+    infeasible := c.GetFitness(constrainedSolution{testSolution{1, 5}, 2})
+    other := c.GetFitness(constrainedSolution{testSolution{2, 3}, 1})
+
+    if diff := fitnessDiff(infeasible, other); diff != 0 {
+        t.Errorf("two infeasible solutions differ by %g, expected 0", diff)
+    }
+    if (&BetterAcceptance{}).Accept(infeasible, other, infeasible, 0) == Accept {
+        t.Errorf("an infeasible candidate improved on an infeasible solution")
+    }
+    if reward := GetImprovementReward(infeasible, 3); reward != 1 {
+        t.Errorf("reward %g from an infeasible solution to a feasible one, expected 1", reward)
+    }
+}
+
+func TestObserveInitialSolution(t *testing.T) {
+    initial := constrainedSolution{testSolution{1, 5}, 0}
+
+    sa := SA[constrainedSolution]()
+    sa.Constraints = Constraints[constrainedSolution](DeathPenalty)
+    sa.InitialTemperature = 0
+    s := initial
+    sa.Improve(&s)
+
+    if !sa.Constraints.HasFeasible || sa.Constraints.BestFeasibleCost != 5 {
+        t.Errorf("initial solution not observed: feasible %v, cost %g", sa.Constraints.HasFeasible, sa.Constraints.BestFeasibleCost)
+    }
+}
+
+func TestElitePoolFitness(t *testing.T) {
+    distance := func(a constrainedSolution, b constrainedSolution) float64 {
+        if a.Id == b.Id {
+            return 0
+        }
+        return 1
+    }
+    pr := PathRelinking[constrainedSolution](nil)
+    pr.Verbose = false
+    pr.Constraints = Constraints[constrainedSolution](DeathPenalty)
+
+    pool := CreateElitePool[constrainedSolution](2, distance)
+    if s := pr.Improve(pool); s.Id != 0 || pr.Relinks != 0 {
+        t.Fatalf("empty pool: got solution %d after %d relinks", s.Id, pr.Relinks)
+    }
+
+    // The infeasible member is cheaper but worse under the death penalty
+    pool.Add(constrainedSolution{testSolution{1, 9}, 0})
+    pool.Add(constrainedSolution{testSolution{2, 3}, 1})
+    best, _ := pool.Best()
+    worst, _ := pool.Worst()
+    if best.Id != 1 || worst.Id != 2 {
+        t.Errorf("best %d and worst %d, expected 1 and 2", best.Id, worst.Id)
+    }
+}
+
+func TestAdaptiveCooling(t *testing.T) {
+    state := CoolingState{Temperature: 100, InitialTemperature: 100, Step: 1, Proposed: 10, Accepted: 5}
+
+    for _, target := range []float64{0, -1} {
+        c := &AdaptiveCooling{Rate: 0.1, TargetAcceptance: target}
+        defaults := &AdaptiveCooling{Rate: 0.1, TargetAcceptance: DefaultTargetAcceptance}
+        if next, expected := c.NextTemperature(state), defaults.NextTemperature(state); next != expected {
+            t.Errorf("target %g: temperature %g, expected %g", target, next, expected)
+        }
+    }
+
+    // Accepting more than the target cools faster, accepting less slower
+    c := &AdaptiveCooling{Rate: 0.1, TargetAcceptance: 0.5}
+    fast := c.NextTemperature(CoolingState{Temperature: 100, Proposed: 10, Accepted: 10})
+    slow := c.NextTemperature(CoolingState{Temperature: 100, Proposed: 10, Accepted: 0})
+    if fast != 80 || math.Abs(slow - 99) > 1e-9 {
+        t.Errorf("temperatures %g and %g, expected 80 and 99", fast, slow)
+    }
+}
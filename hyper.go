@@ -0,0 +1,328 @@
+package hx
+
+import (
+    "fmt"
+    "math"
+    "math/rand"
+)
+
+// Selection hyper-heuristic
+//-------------------------------
+
+// HyperHeuristicAlg treats the improvement and diversification strategies
+// as low-level heuristics. At every iteration, Selector chooses one, it is
+// applied to a copy of the current solution and Acceptance decides whether
+// the result replaces the current solution. The selector is credited with
+// the relative improvement. It is not reset between Improve calls, so that a
+// hyper-heuristic run repeatedly, e.g. as the local search of another
+// algorithm, keeps what it learned; set a new Selector to start afresh.
+type HyperHeuristicAlg[T Solution[T]] struct {
+    HeuristicBase[T]
+    MaxIterations       int
+    MaxNonImprovingIter int
+    Iterations          int
+
+    // Acceptance is the move acceptance criterion. When nil, only
+    // improving moves are accepted.
+    Acceptance AcceptanceCriterion
+}
+
+func HyperHeuristic[T Solution[T]]() HyperHeuristicAlg[T] {
+    return HyperHeuristicAlg[T] {
+        HeuristicBase: CreateHeuristicBase[T](),
+        MaxIterations: 1000,
+        MaxNonImprovingIter: 200,
+    }
+}
+
+// GetHeuristicsCount returns the number of low-level heuristics: the
+// improvement strategies first, then the diversification strategies.
+func (hh *HyperHeuristicAlg[T]) GetHeuristicsCount() int {
+    return len(hh.ImproveStrategiesEx) + len(hh.DiversificationStrategies)
+}
+
+// ApplyHeuristic applies the low-level heuristic h to s.
+func (hh *HyperHeuristicAlg[T]) ApplyHeuristic(h int, s *T) {
+    hh.CurrentStrategy = h
+
+    if h < len(hh.ImproveStrategiesEx) {
+        hh.CurrentCost = (*s).GetCost()
+        hh.Explore(hh.ImproveStrategiesEx[h], s)
+    } else {
+        hh.DiversificationStrategies[h-len(hh.ImproveStrategiesEx)](s)
+    }
+
+    hh.HandleConstraints(s)
+}
+
+func (hh *HyperHeuristicAlg[T]) Improve(s *T) {
+    if hh.Verbose { fmt.Println("[HH STARTING]") }
+    hh.LogCost("Initial Solution", (*s).GetCost())
+    hh.ObserveConstraints(s)
+
+    acceptance := hh.Acceptance
+    if acceptance == nil {
+        acceptance = &BetterAcceptance{}
+    }
+
+    n := hh.GetHeuristicsCount()
+    best := (*s).Copy()
+    nonImprovingIter := 0
+    hh.Iterations = 0
+
+    for hh.Iterations < hh.MaxIterations && nonImprovingIter < hh.MaxNonImprovingIter {
+        h := hh.SelectOperator(n)
+        candidate := (*s).Copy()
+        hh.ApplyHeuristic(h, &candidate)
+
+        fitness := hh.GetFitness(s)
+        candidateFitness := hh.GetFitness(&candidate)
+        hh.CreditOperator(h, fitness, candidateFitness)
+        hh.Iterations++
+
+        if fitnessDiff(hh.GetFitness(&best), candidateFitness) >= ZERO {
+            best = candidate.Copy()
+            hh.BestCost = best.GetCost()
+            hh.Improvements++
+            nonImprovingIter = 0
+            hh.OnImprovement(&candidate, hh)
+            hh.LogCost(fmt.Sprintf("Improvement %-4d", hh.Improvements), hh.BestCost)
+        } else {
+            nonImprovingIter++
+        }
+
+        switch acceptance.Accept(fitness, candidateFitness, hh.GetFitness(&best), nonImprovingIter) {
+        case Accept:
+            *s = candidate
+        case Restart:
+            *s = best.Copy()
+        }
+    }
+
+    *s = best
+    if hh.Constraints != nil {
+        *s = hh.Constraints.Result(best)
+    }
+    hh.LogCost("Final Solution", (*s).GetCost())
+    hh.LogFeasible()
+    hh.LogWeights()
+    if hh.Verbose { fmt.Println("[HH FINISHED]") }
+}
+
+// Selection mechanisms
+//-------------------------------
+
+// ChoiceFunction chooses the heuristic with the highest score, the sum of
+// its recent rewards decayed by Alpha, of its recent rewards when applied
+// right after the previous heuristic decayed by Beta, and of Delta times the
+// number of selections since it was last chosen. Its scores, and the
+// previous heuristic, carry over between Improve calls like the state of the
+// other selectors.
+type ChoiceFunction struct {
+    OperatorStats
+    Alpha float64
+    Beta  float64
+    Delta float64
+
+    F1       []float64
+    F2       [][]float64
+    LastCall []int
+    Scores   []float64
+    // previous is the last heuristic credited plus one, 0 before the first
+    // credit, so that the zero value is ready to use
+    previous int
+    calls    int
+}
+
+func ChoiceFunctionSelector() *ChoiceFunction {
+    return &ChoiceFunction{Alpha: 0.5, Beta: 0.5, Delta: 0.01}
+}
+
+func (sel *ChoiceFunction) ensure(n int) {
+    sel.OperatorStats.ensure(n)
+    for len(sel.F1) < n {
+        sel.F1 = append(sel.F1, 0.0)
+        sel.LastCall = append(sel.LastCall, 0)
+        sel.Scores = append(sel.Scores, 0.0)
+    }
+    for i := range sel.F2 {
+        for len(sel.F2[i]) < n {
+            sel.F2[i] = append(sel.F2[i], 0.0)
+        }
+    }
+    for len(sel.F2) < n {
+        sel.F2 = append(sel.F2, make([]float64, n))
+    }
+}
+
+func (sel *ChoiceFunction) Select(n int) int {
+    sel.ensure(n)
+    sel.calls++
+
+    best := -1
+    for h := 0; h < n; h++ {
+        sel.Scores[h] = sel.F1[h] + sel.Delta*float64(sel.calls-sel.LastCall[h])
+        if sel.previous > 0 {
+            sel.Scores[h] += sel.F2[sel.previous-1][h]
+        }
+        if best < 0 || sel.Scores[h] > sel.Scores[best] {
+            best = h
+        }
+    }
+
+    sel.LastCall[best] = sel.calls
+    return best
+}
+
+func (sel *ChoiceFunction) Credit(op int, reward float64) {
+    sel.ensure(op+1)
+    sel.OperatorStats.Credit(op, reward)
+
+    sel.F1[op] = reward + sel.Alpha*sel.F1[op]
+    if sel.previous > 0 {
+        sel.F2[sel.previous-1][op] = reward + sel.Beta*sel.F2[sel.previous-1][op]
+    }
+    sel.previous = op+1
+}
+
+// GetWeights returns the scores of the last selection.
+func (sel *ChoiceFunction) GetWeights() []float64 {
+    weights := make([]float64, len(sel.Scores))
+    copy(weights, sel.Scores)
+    return weights
+}
+
+// ReinforcementLearning keeps a utility per heuristic, within [Min, Max],
+// which grows by Reward when the heuristic improves and shrinks by Penalty
+// otherwise. The heuristic of highest utility is chosen, ties at random.
+type ReinforcementLearning struct {
+    OperatorStats
+    Min     float64
+    Max     float64
+    Initial float64
+    Reward  float64
+    Penalty float64
+    Utility []float64
+}
+
+func ReinforcementLearningSelector() *ReinforcementLearning {
+    return &ReinforcementLearning{Min: 0, Max: 40, Initial: 20, Reward: 1, Penalty: 1}
+}
+
+func (sel *ReinforcementLearning) ensure(n int) {
+    sel.OperatorStats.ensure(n)
+    for len(sel.Utility) < n {
+        sel.Utility = append(sel.Utility, sel.Initial)
+    }
+}
+
+func (sel *ReinforcementLearning) Select(n int) int {
+    sel.ensure(n)
+
+    best := []int{0}
+    for h := 1; h < n; h++ {
+        if sel.Utility[h] > sel.Utility[best[0]] {
+            best = []int{h}
+        } else if sel.Utility[h] == sel.Utility[best[0]] {
+            best = append(best, h)
+        }
+    }
+    return best[GetRandomInt(0, len(best)-1)]
+}
+
+func (sel *ReinforcementLearning) Credit(op int, reward float64) {
+    sel.ensure(op+1)
+    sel.OperatorStats.Credit(op, reward)
+
+    if reward > 0.0 {
+        sel.Utility[op] = math.Min(sel.Utility[op]+sel.Reward, sel.Max)
+    } else {
+        sel.Utility[op] = math.Max(sel.Utility[op]-sel.Penalty, sel.Min)
+    }
+}
+
+func (sel *ReinforcementLearning) GetWeights() []float64 {
+    weights := make([]float64, len(sel.Utility))
+    copy(weights, sel.Utility)
+    return weights
+}
+
+// ThompsonSampling models the probability of each heuristic to improve with
+// a Beta distribution updated by its successes and failures, and chooses the
+// heuristic with the highest probability sampled from them.
+type ThompsonSampling struct {
+    OperatorStats
+    Successes []float64
+    Failures  []float64
+}
+
+func (sel *ThompsonSampling) ensure(n int) {
+    sel.OperatorStats.ensure(n)
+    for len(sel.Successes) < n {
+        sel.Successes = append(sel.Successes, 0.0)
+        sel.Failures = append(sel.Failures, 0.0)
+    }
+}
+
+func (sel *ThompsonSampling) Select(n int) int {
+    sel.ensure(n)
+
+    best := 0
+    bestSample := -1.0
+    for h := 0; h < n; h++ {
+        if sample := sampleBeta(1+sel.Successes[h], 1+sel.Failures[h]); sample > bestSample {
+            best = h
+            bestSample = sample
+        }
+    }
+    return best
+}
+
+func (sel *ThompsonSampling) Credit(op int, reward float64) {
+    sel.ensure(op+1)
+    sel.OperatorStats.Credit(op, reward)
+
+    if reward > 0.0 {
+        sel.Successes[op]++
+    } else {
+        sel.Failures[op]++
+    }
+}
+
+// GetWeights returns the mean of the Beta distribution of each heuristic.
+func (sel *ThompsonSampling) GetWeights() []float64 {
+    weights := make([]float64, len(sel.Successes))
+    for i := range weights {
+        weights[i] = (1+sel.Successes[i]) / (2+sel.Successes[i]+sel.Failures[i])
+    }
+    return weights
+}
+
+// sampleGamma samples the Gamma(shape, 1) distribution with the method of
+// Marsaglia and Tsang.
+func sampleGamma(shape float64) float64 {
+    if shape < 1.0 {
+        return sampleGamma(shape+1.0) * math.Pow(rand.Float64(), 1.0/shape)
+    }
+
+    d := shape - 1.0/3.0
+    c := 1.0 / math.Sqrt(9.0*d)
+    for {
+        x := rand.NormFloat64()
+        v := 1.0 + c*x
+        if v <= 0.0 {
+            continue
+        }
+        v = v*v*v
+        u := rand.Float64()
+        if math.Log(u) < 0.5*x*x + d - d*v + d*math.Log(v) {
+            return d*v
+        }
+    }
+}
+
+func sampleBeta(a float64, b float64) float64 {
+    x := sampleGamma(a)
+    y := sampleGamma(b)
+    return x / (x+y)
+}
@@ -0,0 +1,109 @@
+package hx
+
+import (
+    "fmt"
+)
+
+// Iterated Greedy
+//-------------------------------
+
+// IGAlg alternates destruction, which removes DestructionSize components of
+// type C from the current solution, and greedy construction, which
+// reinserts them one at a time. The improvement strategies, when any, then
+// run as a VNDAlg on the rebuilt solution.
+type IGAlg[T Solution[T], C any] struct {
+    HeuristicBase[T]
+    MaxNonImprovingIter int
+    DestructionSize     int
+
+    // Destroy removes d components from s, e.g. d random jobs of a flow
+    // shop sequence, and returns them. Reinsert inserts c back into s at
+    // the best place, e.g. the position of least makespan. Both must keep
+    // the cost of s up to date.
+    Destroy  func(s *T, d int) []C
+    Reinsert func(s *T, c C)
+
+    // Acceptance decides whether each rebuilt solution replaces the current
+    // one. When nil, the Metropolis criterion at the constant Temperature
+    // is used, as in LSMCAcceptance.
+    Acceptance  AcceptanceCriterion
+    Temperature float64
+}
+
+func IG[T Solution[T], C any](destroy func(s *T, d int) []C, reinsert func(s *T, c C)) IGAlg[T, C] {
+    return IGAlg[T, C] {
+        HeuristicBase: CreateHeuristicBase[T](),
+        MaxNonImprovingIter: 100,
+        DestructionSize: 4,
+        Destroy: destroy,
+        Reinsert: reinsert,
+        Temperature: 1.0,
+    }
+}
+
+func (ig *IGAlg[T, C]) Improve(s *T) {
+    if ig.Verbose { fmt.Println("[IG STARTING]") }
+    ig.LogCost("Initial Solution", (*s).GetCost())
+    ig.ObserveConstraints(s)
+
+    vnd := VND[T]()
+    vnd.Verbose = false
+    vnd.Mode = ig.VNDMode
+    vnd.Exploration = ig.Exploration
+    vnd.ExplorationK = ig.ExplorationK
+    vnd.Constraints = ig.Constraints
+    SetStrategiesEx(&vnd, ig.ImproveStrategiesEx)
+
+    acceptance := ig.Acceptance
+    if acceptance == nil {
+        acceptance = &LSMCAcceptance{Temperature: ig.Temperature}
+    }
+
+    if len(ig.ImproveStrategiesEx) > 0 {
+        vnd.Improve(s, (*s).GetCost())
+    }
+
+    nonImprovingIter := 0
+    best := (*s).Copy()
+    current := (*s).Copy()
+
+    for nonImprovingIter < ig.MaxNonImprovingIter {
+        for _, c := range ig.Destroy(s, ig.DestructionSize) {
+            ig.Reinsert(s, c)
+        }
+        ig.HandleConstraints(s)
+
+        if len(ig.ImproveStrategiesEx) > 0 {
+            vnd.Improve(s, (*s).GetCost())
+        }
+
+        if fitnessDiff(ig.GetFitness(&best), ig.GetFitness(s)) >= ZERO {
+            ig.Improvements++
+            best = (*s).Copy()
+            ig.BestCost = best.GetCost()
+            nonImprovingIter = 0
+            ig.OnImprovement(s, ig)
+            ig.LogCost(fmt.Sprintf("Improvement %-4d", ig.Improvements), ig.BestCost)
+        } else {
+            nonImprovingIter++
+        }
+
+        switch acceptance.Accept(ig.GetFitness(&current), ig.GetFitness(s), ig.GetFitness(&best), nonImprovingIter) {
+        case Accept:
+            current = (*s).Copy()
+        case Reject:
+            *s = current.Copy()
+        case Restart:
+            *s = best.Copy()
+            current = best.Copy()
+        }
+    }
+
+    *s = best
+    if ig.Constraints != nil {
+        *s = ig.Constraints.Result(best)
+    }
+    ig.LogCost("Final Solution", (*s).GetCost())
+    ig.LogFeasible()
+    if ig.Verbose { fmt.Println("[FINISHED IG]") }
+}
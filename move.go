@@ -0,0 +1,107 @@
+package hx
+
+// Moves and neighborhoods
+//-------------------------------
+
+// Move is a change to a solution that can be evaluated before it is made
+// and reverted after. Delta is the cost difference the move causes on the
+// solution it was generated from. Apply and Undo must keep the cost
+// reported by the solution up to date.
+type Move[T any] interface {
+    Delta() float64
+    Apply(s *T)
+    Undo(s *T)
+}
+
+// AttributedMove is implemented by moves that declare their tabu
+// attributes, for the attribute memory of TSAlg.
+type AttributedMove interface {
+    Attributes() []TabuAttribute
+}
+
+// Neighborhood generates the moves of a solution. A move must stay valid
+// while the solution only changes through Apply and Undo pairs of the other
+// moves generated with it.
+type Neighborhood[T any] interface {
+    // Moves calls yield for every move of s until yield returns false.
+    Moves(s *T, yield func(m Move[T]) bool)
+    // Sample returns a random move of s. It returns false when s has no
+    // moves in this neighborhood.
+    Sample(s *T) (Move[T], bool)
+}
+
+// GetMoveAttributes returns the tabu attributes of m, if it declares any.
+func GetMoveAttributes[T any](m Move[T]) []TabuAttribute {
+    if attributed, ok := m.(AttributedMove); ok {
+        return attributed.Attributes()
+    }
+    return nil
+}
+
+// NeighborhoodStrategyEx turns a neighborhood into an improvement strategy.
+// Moves are compared by Delta without copying s, and the heuristic's
+// exploration mode decides how many improving moves are looked at before
+// the best of them is made.
+func NeighborhoodStrategyEx[T any](n Neighborhood[T]) ImprovementStrategyEx[T] {
+    return func(s *T, heu Heuristic[T]) float64 {
+        mode, k := GetExploration(heu)
+
+        var best Move[T]
+        bestDelta := -ZERO
+        improving := 0
+
+        n.Moves(s, func(m Move[T]) bool {
+            delta := m.Delta()
+            if delta >= -ZERO {
+                return true
+            }
+
+            improving++
+            if delta < bestDelta {
+                best = m
+                bestDelta = delta
+            }
+
+            switch mode {
+            case FirstImprovement:
+                return false
+            case KthImprovement:
+                return improving < k
+            }
+            return true
+        })
+
+        if best == nil {
+            return 0.0
+        }
+
+        best.Apply(s)
+        return bestDelta
+    }
+}
+
+// RandomMoveStrategy turns a neighborhood into a diversification strategy
+// that makes a random move.
+func RandomMoveStrategy[T Solution[T]](n Neighborhood[T]) DiversificationStrategy[T] {
+    return func(s *T) float64 {
+        m, ok := n.Sample(s)
+        if !ok {
+            return 0.0
+        }
+
+        delta := m.Delta()
+        m.Apply(s)
+        return delta
+    }
+}
+
+// AddNeighborhood adds a neighborhood to the improvement strategies.
+func (alg *AlgState[T]) AddNeighborhood(n Neighborhood[T]) {
+    alg.AddImprovingStrategyEx(NeighborhoodStrategyEx(n))
+}
+
+// AddDiversificationNeighborhood adds a strategy making random moves of n
+// to the diversification strategies.
+func (h *HeuristicBase[T]) AddDiversificationNeighborhood(n Neighborhood[T]) {
+    h.AddDiversificationStrategy(RandomMoveStrategy(n))
+}
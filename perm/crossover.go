@@ -0,0 +1,208 @@
+package perm
+
+import (
+    "math/rand"
+
+    "github.com/nidoro/heuristix"
+)
+
+// Crossovers
+//-------------------------------
+// All crossovers satisfy hx.CrossoverStrategy and evaluate the child. The
+// child of empty parents is empty.
+
+// cutPoints returns two random positions a <= b. size must be positive.
+func cutPoints(size int) (int, int) {
+    a := hx.GetRandomInt(0, size-1)
+    b := hx.GetRandomInt(0, size-1)
+    if a > b {
+        a, b = b, a
+    }
+    return a, b
+}
+
+// OX is the order crossover: the child keeps a random segment of the father
+// and the remaining elements in the order they appear in the mother,
+// starting after the segment.
+func OX(father Permutation, mother Permutation) Permutation {
+    size := len(father.Order)
+    if size == 0 {
+        return father.Copy()
+    }
+    order := make([]int, size)
+    used := make([]bool, size)
+
+    a, b := cutPoints(size)
+    for i := a; i <= b; i++ {
+        order[i] = father.Order[i]
+        used[order[i]] = true
+    }
+
+    pos := (b+1) % size
+    for k := 0; k < size; k++ {
+        v := mother.Order[(b+1+k) % size]
+        if used[v] {
+            continue
+        }
+        order[pos] = v
+        used[v] = true
+        pos = (pos+1) % size
+    }
+
+    return New(father.Problem, order)
+}
+
+// PMX is the partially mapped crossover: the child keeps a random segment
+// of the father and the other positions of the mother, following the
+// mapping defined by the segment to resolve conflicts.
+func PMX(father Permutation, mother Permutation) Permutation {
+    size := len(father.Order)
+    if size == 0 {
+        return father.Copy()
+    }
+    order := make([]int, size)
+    inSegment := make([]bool, size)
+    fatherPositions := father.Positions()
+
+    a, b := cutPoints(size)
+    for i := a; i <= b; i++ {
+        order[i] = father.Order[i]
+        inSegment[order[i]] = true
+    }
+
+    for i := 0; i < size; i++ {
+        if i >= a && i <= b {
+            continue
+        }
+        v := mother.Order[i]
+        for inSegment[v] {
+            v = mother.Order[fatherPositions[v]]
+        }
+        order[i] = v
+    }
+
+    return New(father.Problem, order)
+}
+
+// CX is the cycle crossover: the positions are split in cycles and the
+// child takes alternate cycles from the father and the mother, so every
+// element keeps the position it has in one of the parents.
+func CX(father Permutation, mother Permutation) Permutation {
+    size := len(father.Order)
+    order := make([]int, size)
+    visited := make([]bool, size)
+    fatherPositions := father.Positions()
+
+    fromFather := true
+    for start := 0; start < size; start++ {
+        if visited[start] {
+            continue
+        }
+
+        for i := start; !visited[i]; i = fatherPositions[mother.Order[i]] {
+            visited[i] = true
+            if fromFather {
+                order[i] = father.Order[i]
+            } else {
+                order[i] = mother.Order[i]
+            }
+        }
+
+        fromFather = !fromFather
+    }
+
+    return New(father.Problem, order)
+}
+
+// ERX is the edge recombination crossover: the child is built element by
+// element, moving to the neighbor, in either parent, with the fewest
+// remaining neighbors. Permutations are treated as cycles.
+func ERX(father Permutation, mother Permutation) Permutation {
+    size := len(father.Order)
+    if size == 0 {
+        return father.Copy()
+    }
+    order := make([]int, 0, size)
+    used := make([]bool, size)
+
+    neighbors := make([]map[int]bool, size)
+    for i := range neighbors {
+        neighbors[i] = make(map[int]bool, 4)
+    }
+    for _, parent := range [][]int{father.Order, mother.Order} {
+        for i, v := range parent {
+            neighbors[v][parent[(i+1) % size]] = true
+            neighbors[v][parent[(i-1+size) % size]] = true
+        }
+    }
+    for v := range neighbors {
+        delete(neighbors[v], v)
+    }
+
+    current := father.Order[0]
+    for {
+        order = append(order, current)
+        used[current] = true
+        for v := range neighbors[current] {
+            delete(neighbors[v], current)
+        }
+
+        if len(order) == size {
+            break
+        }
+
+        next := -1
+        for v := range neighbors[current] {
+            if next == -1 || len(neighbors[v]) < len(neighbors[next]) ||
+                (len(neighbors[v]) == len(neighbors[next]) && rand.Float64() < 0.5) {
+                next = v
+            }
+        }
+
+        if next == -1 {
+            remaining := make([]int, 0, size-len(order))
+            for v := 0; v < size; v++ {
+                if !used[v] {
+                    remaining = append(remaining, v)
+                }
+            }
+            next = remaining[hx.GetRandomInt(0, len(remaining)-1)]
+        }
+
+        current = next
+    }
+
+    return New(father.Problem, order)
+}
+
+// PBX is the position-based crossover: the child keeps the father's
+// elements at a random set of positions and fills the others with the
+// remaining elements in the order they appear in the mother.
+func PBX(father Permutation, mother Permutation) Permutation {
+    size := len(father.Order)
+    order := make([]int, size)
+    fixed := make([]bool, size)
+    used := make([]bool, size)
+
+    for i := 0; i < size; i++ {
+        if rand.Float64() < 0.5 {
+            fixed[i] = true
+            order[i] = father.Order[i]
+            used[order[i]] = true
+        }
+    }
+
+    pos := 0
+    for _, v := range mother.Order {
+        if used[v] {
+            continue
+        }
+        for fixed[pos] {
+            pos++
+        }
+        order[pos] = v
+        pos++
+    }
+
+    return New(father.Problem, order)
+}
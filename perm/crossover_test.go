@@ -0,0 +1,48 @@
+package perm
+
+import (
+    "testing"
+
+    "github.com/nidoro/heuristix"
+)
+
+func TestCrossovers(t *testing.T) {
+    crossovers := map[string]hx.CrossoverStrategy[Permutation]{
+        "OX": OX,
+        "PMX": PMX,
+        "CX": CX,
+        "ERX": ERX,
+        "PBX": PBX,
+    }
+
+    for name, crossover := range crossovers {
+        for _, size := range []int{1, 2, 9} {
+            problem := createTestProblem(size)
+            for k := 0; k < 100; k++ {
+                child := crossover(Random(problem), Random(problem))
+                if len(child.Order) != size {
+                    t.Fatalf("%s: child %v, expected %d elements", name, child.Order, size)
+                }
+                checkPermutation(t, name, child)
+            }
+        }
+
+        empty := Identity(createTestProblem(0))
+        if child := crossover(empty, empty); len(child.Order) != 0 {
+            t.Errorf("%s: child %v of empty parents", name, child.Order)
+        }
+    }
+}
+
+func TestCXKeepsPositions(t *testing.T) {
+    problem := createTestProblem(9)
+    for k := 0; k < 100; k++ {
+        father, mother := Random(problem), Random(problem)
+        child := CX(father, mother)
+        for i, v := range child.Order {
+            if v != father.Order[i] && v != mother.Order[i] {
+                t.Fatalf("position %d of %v is in neither %v nor %v", i, child.Order, father.Order, mother.Order)
+            }
+        }
+    }
+}
@@ -0,0 +1,309 @@
+package perm
+
+import (
+    "github.com/nidoro/heuristix"
+)
+
+// MoveKind identifies the change a Move makes to a permutation.
+type MoveKind int
+
+const (
+    // SwapMove exchanges the elements at positions I and J.
+    SwapMove MoveKind = iota
+    // InsertionMove removes the element at position I and inserts it back
+    // so that it ends up at position J.
+    InsertionMove
+    // TwoOptMove reverses the segment between positions I and J, inclusive.
+    TwoOptMove
+    // OrOptMove moves the K elements starting at position I so that they
+    // end up starting at position J.
+    OrOptMove
+    // ThreeOptMove exchanges the adjacent segments [I, J) and [J, K),
+    // keeping their orientation.
+    ThreeOptMove
+)
+
+// Element is the tabu attribute of a moved element.
+type Element int
+
+// Move is a change to a Permutation. It satisfies hx.Move and
+// hx.AttributedMove.
+type Move struct {
+    Kind MoveKind
+    I    int
+    J    int
+    K    int
+
+    solution  *Permutation
+    delta     float64
+    evaluated bool
+    elements  []int
+}
+
+func newMove(s *Permutation, kind MoveKind, i int, j int, k int) *Move {
+    m := &Move{Kind: kind, I: i, J: j, K: k, solution: s}
+
+    switch kind {
+    case SwapMove, TwoOptMove:
+        m.elements = []int{s.Order[i], s.Order[j]}
+    case InsertionMove:
+        m.elements = []int{s.Order[i]}
+    case OrOptMove:
+        m.elements = []int{s.Order[i], s.Order[i+k-1]}
+    case ThreeOptMove:
+        m.elements = []int{s.Order[i], s.Order[j], s.Order[k-1]}
+    }
+
+    return m
+}
+
+// Change makes the move on order, without touching any cost.
+func (m *Move) Change(order []int) {
+    switch m.Kind {
+    case SwapMove:
+        order[m.I], order[m.J] = order[m.J], order[m.I]
+    case InsertionMove:
+        moveSegment(order, m.I, 1, m.J)
+    case TwoOptMove:
+        reverse(order, m.I, m.J+1)
+    case OrOptMove:
+        moveSegment(order, m.I, m.K, m.J)
+    case ThreeOptMove:
+        rotate(order, m.I, m.K, m.J-m.I)
+    }
+}
+
+// Revert undoes Change on order.
+func (m *Move) Revert(order []int) {
+    switch m.Kind {
+    case SwapMove:
+        order[m.I], order[m.J] = order[m.J], order[m.I]
+    case InsertionMove:
+        moveSegment(order, m.J, 1, m.I)
+    case TwoOptMove:
+        reverse(order, m.I, m.J+1)
+    case OrOptMove:
+        moveSegment(order, m.J, m.K, m.I)
+    case ThreeOptMove:
+        rotate(order, m.I, m.K, m.K-m.J)
+    }
+}
+
+// Delta returns the cost difference the move causes on the permutation it
+// was generated from. It is computed once, before the move is applied.
+func (m *Move) Delta() float64 {
+    if m.evaluated {
+        return m.delta
+    }
+
+    s := m.solution
+    if s.Problem.Delta != nil {
+        m.delta = s.Problem.Delta(s.Order, m)
+    } else {
+        m.Change(s.Order)
+        m.delta = s.Problem.Cost(s.Order) - s.Cost
+        m.Revert(s.Order)
+    }
+
+    m.evaluated = true
+    return m.delta
+}
+
+func (m *Move) Apply(s *Permutation) {
+    delta := m.Delta()
+    m.Change(s.Order)
+    s.Cost += delta
+}
+
+func (m *Move) Undo(s *Permutation) {
+    m.Revert(s.Order)
+    s.Cost -= m.delta
+}
+
+// Attributes returns the elements at the ends of the moved segments.
+func (m *Move) Attributes() []hx.TabuAttribute {
+    attributes := make([]hx.TabuAttribute, len(m.elements))
+    for i, e := range m.elements {
+        attributes[i] = hx.TabuAttribute{Key: Element(e)}
+    }
+    return attributes
+}
+
+func reverse(order []int, from int, to int) {
+    for i, j := from, to-1; i < j; i, j = i+1, j-1 {
+        order[i], order[j] = order[j], order[i]
+    }
+}
+
+// rotate shifts order[from:to] left by shift positions.
+func rotate(order []int, from int, to int, shift int) {
+    if shift <= 0 || shift >= to-from {
+        return
+    }
+    reverse(order, from, from+shift)
+    reverse(order, from+shift, to)
+    reverse(order, from, to)
+}
+
+// moveSegment moves the length elements starting at i so that they start
+// at j.
+func moveSegment(order []int, i int, length int, j int) {
+    if j < i {
+        rotate(order, j, i+length, i-j)
+    } else if j > i {
+        rotate(order, i, j+length, length)
+    }
+}
+
+// Neighborhoods
+//-------------------------------
+
+// Neighborhood generates the moves of one kind. It satisfies
+// hx.Neighborhood.
+type Neighborhood struct {
+    Kind      MoveKind
+    // MaxLength is the longest segment moved by OrOptMove. Below 1, the
+    // neighborhood is empty.
+    MaxLength int
+}
+
+func SwapNeighborhood() *Neighborhood {
+    return &Neighborhood{Kind: SwapMove}
+}
+
+func InsertionNeighborhood() *Neighborhood {
+    return &Neighborhood{Kind: InsertionMove}
+}
+
+func TwoOptNeighborhood() *Neighborhood {
+    return &Neighborhood{Kind: TwoOptMove}
+}
+
+func OrOptNeighborhood(maxLength int) *Neighborhood {
+    return &Neighborhood{Kind: OrOptMove, MaxLength: maxLength}
+}
+
+func ThreeOptNeighborhood() *Neighborhood {
+    return &Neighborhood{Kind: ThreeOptMove}
+}
+
+func (n *Neighborhood) Moves(s *Permutation, yield func(m hx.Move[Permutation]) bool) {
+    size := len(s.Order)
+
+    switch n.Kind {
+    case SwapMove, TwoOptMove:
+        for i := 0; i < size; i++ {
+            for j := i+1; j < size; j++ {
+                if !yield(newMove(s, n.Kind, i, j, 0)) {
+                    return
+                }
+            }
+        }
+    case InsertionMove:
+        for i := 0; i < size; i++ {
+            for j := 0; j < size; j++ {
+                // Moving i to i-1 is the same as moving i-1 to i
+                if j == i || j == i-1 {
+                    continue
+                }
+                if !yield(newMove(s, n.Kind, i, j, 0)) {
+                    return
+                }
+            }
+        }
+    case OrOptMove:
+        for length := 1; length <= n.MaxLength; length++ {
+            for i := 0; i+length <= size; i++ {
+                for j := 0; j+length <= size; j++ {
+                    if j == i {
+                        continue
+                    }
+                    if !yield(newMove(s, n.Kind, i, j, length)) {
+                        return
+                    }
+                }
+            }
+        }
+    case ThreeOptMove:
+        for i := 0; i < size; i++ {
+            for j := i+1; j < size; j++ {
+                for k := j+1; k <= size; k++ {
+                    if !yield(newMove(s, n.Kind, i, j, k)) {
+                        return
+                    }
+                }
+            }
+        }
+    }
+}
+
+func (n *Neighborhood) Sample(s *Permutation) (hx.Move[Permutation], bool) {
+    size := len(s.Order)
+    if size < 2 {
+        return nil, false
+    }
+
+    switch n.Kind {
+    case SwapMove, TwoOptMove:
+        i := hx.GetRandomInt(0, size-2)
+        j := hx.GetRandomInt(i+1, size-1)
+        return newMove(s, n.Kind, i, j, 0), true
+    case InsertionMove:
+        i := hx.GetRandomInt(0, size-1)
+        j := hx.GetRandomInt(0, size-2)
+        if j >= i {
+            j++
+        }
+        return newMove(s, n.Kind, i, j, 0), true
+    case OrOptMove:
+        if n.MaxLength < 1 {
+            return nil, false
+        }
+        length := hx.GetRandomInt(1, hx.Min(n.MaxLength, size-1))
+        i := hx.GetRandomInt(0, size-length)
+        j := hx.GetRandomInt(0, size-length-1)
+        if j >= i {
+            j++
+        }
+        return newMove(s, n.Kind, i, j, length), true
+    case ThreeOptMove:
+        i := hx.GetRandomInt(0, size-2)
+        j := hx.GetRandomInt(i+1, size-1)
+        k := hx.GetRandomInt(j+1, size)
+        return newMove(s, n.Kind, i, j, k), true
+    }
+
+    return nil, false
+}
+
+// Path relinking
+//-------------------------------
+
+// RelinkingMoves returns the swaps that put an element of s at its position
+// in guide. It satisfies the RelinkingMoves of hx.PathRelinkingAlg.
+func RelinkingMoves(s *Permutation, guide Permutation) []hx.Move[Permutation] {
+    moves := []hx.Move[Permutation]{}
+    positions := s.Positions()
+
+    for i, e := range guide.Order {
+        if s.Order[i] == e {
+            continue
+        }
+        j := positions[e]
+        moves = append(moves, newMove(s, SwapMove, hx.Min(i, j), hx.Max(i, j), 0))
+    }
+
+    return moves
+}
+
+// Distance returns the number of positions holding different elements in p
+// and p2.
+func Distance(p Permutation, p2 Permutation) float64 {
+    distance := 0
+    for i := range p.Order {
+        if p.Order[i] != p2.Order[i] {
+            distance++
+        }
+    }
+    return float64(distance)
+}
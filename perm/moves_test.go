@@ -0,0 +1,116 @@
+package perm
+
+import (
+    "math"
+    "testing"
+
+    "github.com/nidoro/heuristix"
+)
+
+// createTestProblem returns a problem whose cost depends on the position of
+// every element, so that every move changes it.
+func createTestProblem(size int) *Problem {
+    return &Problem{
+        Size: size,
+        Cost: func(order []int) float64 {
+            cost := 0.0
+            for i, v := range order {
+                cost += float64((i+1)*(v+1)*(v+1))
+            }
+            return cost
+        },
+    }
+}
+
+func checkPermutation(t *testing.T, name string, p Permutation) {
+    t.Helper()
+    seen := make([]bool, len(p.Order))
+    for _, v := range p.Order {
+        if v < 0 || v >= len(p.Order) || seen[v] {
+            t.Fatalf("%s: %v is not a permutation", name, p.Order)
+        }
+        seen[v] = true
+    }
+    if math.Abs(p.Cost - p.Problem.Cost(p.Order)) > 1e-9 {
+        t.Fatalf("%s: cost %g, expected %g", name, p.Cost, p.Problem.Cost(p.Order))
+    }
+}
+
+// checkRoundTrip applies m to s, checks the result and undoes it.
+func checkRoundTrip(t *testing.T, name string, s *Permutation, m hx.Move[Permutation]) {
+    t.Helper()
+    before := s.Copy()
+    m.Apply(s)
+    checkPermutation(t, name, *s)
+    if s.Compare(before) {
+        t.Fatalf("%s: %+v did not change %v", name, m, before.Order)
+    }
+    m.Undo(s)
+    if !s.Compare(before) || s.Cost != before.Cost {
+        t.Fatalf("%s: undo gave %v cost %g, expected %v cost %g", name, s.Order, s.Cost, before.Order, before.Cost)
+    }
+}
+
+func TestMoves(t *testing.T) {
+    const size = 7
+    cases := []struct {
+        name         string
+        neighborhood *Neighborhood
+        count        int
+    }{
+        {"swap", SwapNeighborhood(), size*(size-1)/2},
+        {"insertion", InsertionNeighborhood(), (size-1)*(size-1)},
+        {"2-opt", TwoOptNeighborhood(), size*(size-1)/2},
+        // (size-l+1)*(size-l) moves of each length l
+        {"or-opt", OrOptNeighborhood(3), 7*6 + 6*5 + 5*4},
+        {"or-opt 0", OrOptNeighborhood(0), 0},
+        // i < j < k <= size
+        {"3-opt", ThreeOptNeighborhood(), (size+1)*size*(size-1)/6},
+    }
+
+    for _, c := range cases {
+        s := Random(createTestProblem(size))
+        count := 0
+        c.neighborhood.Moves(&s, func(m hx.Move[Permutation]) bool {
+            checkRoundTrip(t, c.name, &s, m)
+            count++
+            return true
+        })
+        if count != c.count {
+            t.Errorf("%s: %d moves, expected %d", c.name, count, c.count)
+        }
+    }
+}
+
+func TestSample(t *testing.T) {
+    neighborhoods := map[string]*Neighborhood{
+        "swap": SwapNeighborhood(),
+        "insertion": InsertionNeighborhood(),
+        "2-opt": TwoOptNeighborhood(),
+        "or-opt": OrOptNeighborhood(3),
+        "3-opt": ThreeOptNeighborhood(),
+    }
+
+    for name, n := range neighborhoods {
+        for _, size := range []int{2, 3, 8} {
+            s := Random(createTestProblem(size))
+            for k := 0; k < 100; k++ {
+                m, ok := n.Sample(&s)
+                if !ok {
+                    t.Fatalf("%s: no move sampled from %v", name, s.Order)
+                }
+                checkRoundTrip(t, name, &s, m)
+            }
+        }
+
+        s := Identity(createTestProblem(1))
+        if _, ok := n.Sample(&s); ok {
+            t.Errorf("%s: move sampled from a single element", name)
+        }
+    }
+
+    s := Random(createTestProblem(5))
+    if _, ok := OrOptNeighborhood(0).Sample(&s); ok {
+        t.Errorf("or-opt: move sampled with MaxLength 0")
+    }
+}
@@ -0,0 +1,119 @@
+// Package perm provides a permutation solution type with ready-made
+// neighborhoods and crossovers that plug into the hx algorithms.
+package perm
+
+import (
+    "encoding/binary"
+    "hash/fnv"
+    "math/rand"
+)
+
+// Problem holds what all the permutations of a problem share. Cost is the
+// objective function. Delta, when set, returns the cost difference move m
+// causes on order without changing it; otherwise moves are evaluated by
+// making them, calling Cost and undoing them.
+type Problem struct {
+    Size  int
+    Cost  func(order []int) float64
+    Delta func(order []int, m *Move) float64
+}
+
+// Placement is Element at Position.
+type Placement struct {
+    Position int
+    Element  int
+}
+
+// Permutation is an ordering of the integers 0..Size-1. It satisfies
+// hx.ComparableSolution, hx.HashableSolution and hx.AttributedSolution.
+type Permutation struct {
+    Problem *Problem
+    Order   []int
+    Cost    float64
+}
+
+// New creates a permutation with the given order and evaluates its cost.
+func New(problem *Problem, order []int) Permutation {
+    p := Permutation{
+        Problem: problem,
+        Order: make([]int, len(order)),
+    }
+    copy(p.Order, order)
+    p.Evaluate()
+    return p
+}
+
+// Identity creates the permutation 0, 1, ..., Size-1.
+func Identity(problem *Problem) Permutation {
+    order := make([]int, problem.Size)
+    for i := range order {
+        order[i] = i
+    }
+    return New(problem, order)
+}
+
+// Random creates a uniformly random permutation.
+func Random(problem *Problem) Permutation {
+    return New(problem, rand.Perm(problem.Size))
+}
+
+// Evaluate recomputes the cost with the problem's cost function.
+func (p *Permutation) Evaluate() {
+    p.Cost = p.Problem.Cost(p.Order)
+}
+
+func (p Permutation) GetCost() float64 {
+    return p.Cost
+}
+
+func (p Permutation) Copy() Permutation {
+    result := p
+    result.Order = make([]int, len(p.Order))
+    copy(result.Order, p.Order)
+    return result
+}
+
+func (p Permutation) Compare(p2 Permutation) bool {
+    if len(p.Order) != len(p2.Order) {
+        return false
+    }
+
+    for i := range p.Order {
+        if p.Order[i] != p2.Order[i] {
+            return false
+        }
+    }
+
+    return true
+}
+
+func (p Permutation) Hash() uint64 {
+    h := fnv.New64a()
+    b := make([]byte, 8)
+
+    for _, v := range p.Order {
+        binary.LittleEndian.PutUint64(b, uint64(v))
+        h.Write(b)
+    }
+
+    return h.Sum64()
+}
+
+// Positions returns the inverse permutation: the index of each element.
+func (p Permutation) Positions() []int {
+    positions := make([]int, len(p.Order))
+    for i, v := range p.Order {
+        positions[v] = i
+    }
+    return positions
+}
+
+// GetAttributes returns one Placement per position, so tabu search can
+// count how often each position held each element.
+func (p Permutation) GetAttributes() []any {
+    attributes := make([]any, len(p.Order))
+    for i, v := range p.Order {
+        attributes[i] = Placement{Position: i, Element: v}
+    }
+    return attributes
+}
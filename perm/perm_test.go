@@ -0,0 +1,28 @@
+package perm
+
+import (
+    "testing"
+
+    "github.com/nidoro/heuristix"
+)
+
+func TestGetAttributes(t *testing.T) {
+    p := New(createTestProblem(4), []int{2, 0, 3, 1})
+
+    var s hx.AttributedSolution = p
+    attributes := s.GetAttributes()
+    if len(attributes) != 4 {
+        t.Fatalf("%d attributes, expected 4", len(attributes))
+    }
+    for i, attr := range attributes {
+        if attr != any(Placement{Position: i, Element: p.Order[i]}) {
+            t.Errorf("attribute %d is %v, expected element %d at %d", i, attr, p.Order[i], i)
+        }
+    }
+
+    // The same element at another position is another attribute
+    q := New(p.Problem, []int{0, 2, 3, 1})
+    if q.GetAttributes()[0] == attributes[0] || q.GetAttributes()[2] != attributes[2] {
+        t.Errorf("attributes %v and %v", q.GetAttributes(), attributes)
+    }
+}
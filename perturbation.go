@@ -0,0 +1,82 @@
+package hx
+
+// Perturbation strength
+//-------------------------------
+
+// PerturbationOutcome describes an iteration of ILSAlg.
+type PerturbationOutcome struct {
+    Improved bool // the best solution improved
+    Accepted bool // the new local optimum became the current solution
+    Escaped  bool // the new local optimum differs in cost from the current one
+}
+
+// PerturbationStrength decides how many random diversification moves
+// ILSAlg applies before each local search.
+type PerturbationStrength interface {
+    GetStrength() int
+    Update(outcome PerturbationOutcome)
+}
+
+// FixedStrength always applies Strength moves.
+type FixedStrength struct {
+    Strength int
+}
+
+func (p *FixedStrength) GetStrength() int {
+    return p.Strength
+}
+
+func (p *FixedStrength) Update(outcome PerturbationOutcome) {}
+
+// LinearStrength adds Step moves after every iteration that does not improve
+// the best solution, up to Max, and goes back to Min when it improves.
+type LinearStrength struct {
+    Min      int
+    Step     int
+    Max      int
+    Strength int
+}
+
+func LinearPerturbation(min int, step int, max int) *LinearStrength {
+    return &LinearStrength{Min: min, Step: step, Max: max, Strength: min}
+}
+
+func (p *LinearStrength) GetStrength() int {
+    return p.Strength
+}
+
+func (p *LinearStrength) Update(outcome PerturbationOutcome) {
+    if outcome.Improved {
+        p.Strength = p.Min
+    } else {
+        p.Strength = Min(p.Strength+p.Step, p.Max)
+    }
+}
+
+// AdaptiveStrength grows by one move whenever the local search falls back
+// into the current local optimum, shrinks by one when a different local
+// optimum is accepted and goes back to Min when the best solution improves.
+type AdaptiveStrength struct {
+    Min      int
+    Max      int
+    Strength int
+}
+
+func AdaptivePerturbation(min int, max int) *AdaptiveStrength {
+    return &AdaptiveStrength{Min: min, Max: max, Strength: min}
+}
+
+func (p *AdaptiveStrength) GetStrength() int {
+    return p.Strength
+}
+
+func (p *AdaptiveStrength) Update(outcome PerturbationOutcome) {
+    switch {
+    case outcome.Improved:
+        p.Strength = p.Min
+    case !outcome.Escaped:
+        p.Strength = Min(p.Strength+1, p.Max)
+    case outcome.Accepted:
+        p.Strength = Max(p.Strength-1, p.Min)
+    }
+}
@@ -0,0 +1,376 @@
+package hx
+
+import (
+    "fmt"
+    "math"
+    "sort"
+)
+
+// Scatter Search
+//-------------------------------
+
+// ScatterSearchAlg evolves a small reference set made of the QualitySize
+// best solutions found and of DiversitySize solutions far from them.
+// Subsets of the reference set are combined with the crossover strategies
+// and the results improved with the improvement strategies, run as a
+// VNDAlg, before updating the reference set.
+type ScatterSearchAlg[T Solution[T]] struct {
+    HeuristicBase[T]
+    QualitySize   int
+    DiversitySize int
+    Distance      func(a T, b T) float64
+    CrossoverStrategies []CrossoverStrategy[T]
+
+    // SubsetTypes is the number of subset types combined, from 1 to 4:
+    // pairs, pairs plus the best solution not in them, triples plus the
+    // best solution not in them, and the i best solutions for i >= 5.
+    SubsetTypes int
+    // DynamicUpdate admits each improved combination into the reference
+    // set as soon as it is created. Otherwise the reference set is updated
+    // once all the subsets have been combined.
+    DynamicUpdate bool
+
+    // When the reference set does not change, its diversity part is
+    // rebuilt, at most MaxRebuilds times, from solutions obtained by
+    // applying RebuildStrength random diversification moves to reference
+    // solutions and improving them.
+    MaxRebuilds     int
+    RebuildStrength int
+    RebuildSize     int
+    Rebuilds        int
+    Iterations      int
+
+    RefSet []T
+    // isNew tells which members of RefSet entered since their subsets
+    // were last combined.
+    isNew []bool
+}
+
+func ScatterSearch[T Solution[T]](distance func(a T, b T) float64) ScatterSearchAlg[T] {
+    return ScatterSearchAlg[T] {
+        HeuristicBase: CreateHeuristicBase[T](),
+        QualitySize: 5,
+        DiversitySize: 5,
+        Distance: distance,
+        SubsetTypes: 1,
+        DynamicUpdate: false,
+        MaxRebuilds: 3,
+        RebuildStrength: 10,
+        RebuildSize: 20,
+    }
+}
+
+func (ss *ScatterSearchAlg[T]) AddCrossoverStrategy(strategy CrossoverStrategy[T]) {
+    ss.CrossoverStrategies = append(ss.CrossoverStrategies, strategy)
+}
+
+func (ss *ScatterSearchAlg[T]) minDistance(s T, solutions []T) float64 {
+    d := math.Inf(1)
+    for _, s2 := range solutions {
+        d = math.Min(d, ss.Distance(s, s2))
+    }
+    return d
+}
+
+// isDuplicate reports whether s is at distance 0 from a member of RefSet.
+func (ss *ScatterSearchAlg[T]) isDuplicate(s T) bool {
+    return ss.minDistance(s, ss.RefSet) < ZERO
+}
+
+// BuildRefSet builds the reference set from candidates: the QualitySize
+// best distinct ones, then, one at a time, the DiversitySize ones farthest
+// from the reference set. When keep is positive, only the keep best
+// members of the current reference set are kept and candidates complete it.
+func (ss *ScatterSearchAlg[T]) BuildRefSet(candidates []T, keep int) {
+    ss.SortByFitness(candidates)
+
+    if keep > len(ss.RefSet) {
+        keep = len(ss.RefSet)
+    }
+    ss.sortRefSet()
+    ss.RefSet = ss.RefSet[:keep]
+    ss.isNew = ss.isNew[:keep]
+
+    used := make([]bool, len(candidates))
+    for i, s := range candidates {
+        if len(ss.RefSet) >= ss.QualitySize {
+            break
+        }
+        if !ss.isDuplicate(s) {
+            ss.RefSet = append(ss.RefSet, s)
+            ss.isNew = append(ss.isNew, true)
+            used[i] = true
+        }
+    }
+
+    for len(ss.RefSet) < ss.QualitySize + ss.DiversitySize {
+        farthest := -1
+        farthestDistance := 0.0
+        for i, s := range candidates {
+            if used[i] {
+                continue
+            }
+            if d := ss.minDistance(s, ss.RefSet); d > farthestDistance {
+                farthest = i
+                farthestDistance = d
+            }
+        }
+
+        if farthest < 0 {
+            break
+        }
+
+        ss.RefSet = append(ss.RefSet, candidates[farthest])
+        ss.isNew = append(ss.isNew, true)
+        used[farthest] = true
+    }
+}
+
+// GenerateSubsets returns the subsets of reference set indices to combine,
+// each in increasing order and only once. Only subsets with at least one
+// new member are generated.
+func (ss *ScatterSearchAlg[T]) GenerateSubsets() [][]int {
+    ss.sortRefSet()
+    subsets := [][]int{}
+    generated := make(map[string]bool)
+
+    hasNew := func(subset []int) bool {
+        for _, i := range subset {
+            if ss.isNew[i] {
+                return true
+            }
+        }
+        return false
+    }
+
+    add := func(subset []int) {
+        sort.Ints(subset)
+        key := fmt.Sprint(subset)
+        if hasNew(subset) && !generated[key] {
+            generated[key] = true
+            subsets = append(subsets, subset)
+        }
+    }
+
+    // bestOutside returns the best member not in subset
+    bestOutside := func(subset []int) int {
+        for i := range ss.RefSet {
+            if !Contains(subset, i) {
+                return i
+            }
+        }
+        return -1
+    }
+
+    n := len(ss.RefSet)
+    pairs := [][]int{}
+    for i := 0; i < n; i++ {
+        for j := i+1; j < n; j++ {
+            pairs = append(pairs, []int{i, j})
+        }
+    }
+
+    for _, pair := range pairs {
+        add(pair)
+    }
+
+    // Different pairs can lead to the same triple or quadruple
+    if ss.SubsetTypes >= 2 {
+        for _, pair := range pairs {
+            k := bestOutside(pair)
+            if k < 0 {
+                continue
+            }
+            triple := append([]int{k}, pair...)
+            add(triple)
+
+            if ss.SubsetTypes >= 3 {
+                if k := bestOutside(triple); k >= 0 {
+                    add(append([]int{k}, triple...))
+                }
+            }
+        }
+    }
+
+    if ss.SubsetTypes >= 4 {
+        for size := 5; size <= n; size++ {
+            subset := make([]int, size)
+            for i := range subset {
+                subset[i] = i
+            }
+            add(subset)
+        }
+    }
+
+    return subsets
+}
+
+// sortRefSet sorts the reference set by fitness, keeping the new members
+// flagged.
+func (ss *ScatterSearchAlg[T]) sortRefSet() {
+    order := make([]int, len(ss.RefSet))
+    for i := range order {
+        order[i] = i
+    }
+    sort.SliceStable(order, func(i, j int) bool {
+        return ss.GetFitness(&ss.RefSet[order[i]]) < ss.GetFitness(&ss.RefSet[order[j]])
+    })
+
+    refSet := make([]T, len(order))
+    isNew := make([]bool, len(order))
+    for i, k := range order {
+        refSet[i] = ss.RefSet[k]
+        isNew[i] = ss.isNew[k]
+    }
+    ss.RefSet = refSet
+    ss.isNew = isNew
+}
+
+// Combine folds the crossover strategies over the solutions of subset.
+func (ss *ScatterSearchAlg[T]) Combine(subset []int) T {
+    crossover := ss.CrossoverStrategies[GetRandomInt(0, len(ss.CrossoverStrategies)-1)]
+
+    child := crossover(ss.RefSet[subset[0]], ss.RefSet[subset[1]])
+    for _, i := range subset[2:] {
+        child = crossover(child, ss.RefSet[i])
+    }
+
+    ss.HandleConstraints(&child)
+    return child
+}
+
+// UpdateRefSet admits s in place of the worst member of the reference set
+// when it is better and not a duplicate. It reports whether s was admitted.
+func (ss *ScatterSearchAlg[T]) UpdateRefSet(s T) bool {
+    if ss.isDuplicate(s) {
+        return false
+    }
+
+    worst := 0
+    for i := range ss.RefSet {
+        if ss.GetFitness(&ss.RefSet[i]) > ss.GetFitness(&ss.RefSet[worst]) {
+            worst = i
+        }
+    }
+
+    if ss.GetFitness(&s) >= ss.GetFitness(&ss.RefSet[worst]) {
+        return false
+    }
+
+    ss.RefSet[worst] = s
+    ss.isNew[worst] = true
+    return true
+}
+
+// Improve builds the reference set from population and returns the best
+// solution found. With an empty population there is nothing to improve,
+// and it returns the zero T.
+func (ss *ScatterSearchAlg[T]) Improve(population []T) T {
+    if len(population) == 0 {
+        if ss.Verbose { fmt.Println("[SS EMPTY POPULATION]") }
+        var zero T
+        return zero
+    }
+
+    if ss.Verbose { fmt.Println("[SS STARTING]") }
+
+    vnd := VND[T]()
+    vnd.Verbose = false
+    vnd.Mode = ss.VNDMode
+    vnd.Exploration = ss.Exploration
+    vnd.ExplorationK = ss.ExplorationK
+    vnd.Constraints = ss.Constraints
+    SetStrategiesEx(&vnd, ss.ImproveStrategiesEx)
+
+    improve := func(s *T) {
+        vnd.Improve(s, (*s).GetCost())
+    }
+
+    candidates := make([]T, len(population))
+    for i := range population {
+        ss.ObserveConstraints(&population[i])
+        candidates[i] = population[i].Copy()
+        improve(&candidates[i])
+    }
+
+    ss.RefSet = nil
+    ss.isNew = nil
+    ss.BuildRefSet(candidates, 0)
+
+    best := ss.RefSet[0].Copy()
+    ss.LogCost("Initial Solution", best.GetCost())
+
+    ss.Rebuilds = 0
+    ss.Iterations = 0
+
+    for {
+        subsets := ss.GenerateSubsets()
+        for i := range ss.isNew {
+            ss.isNew[i] = false
+        }
+
+        changed := false
+        pool := []T{}
+
+        for _, subset := range subsets {
+            child := ss.Combine(subset)
+            improve(&child)
+
+            if ss.DynamicUpdate {
+                changed = ss.UpdateRefSet(child) || changed
+            } else {
+                pool = append(pool, child)
+            }
+        }
+
+        if !ss.DynamicUpdate {
+            ss.SortByFitness(pool)
+            for _, child := range pool {
+                changed = ss.UpdateRefSet(child) || changed
+            }
+        }
+
+        ss.Iterations++
+
+        for i := range ss.RefSet {
+            if fitnessDiff(ss.GetFitness(&best), ss.GetFitness(&ss.RefSet[i])) >= ZERO {
+                best = ss.RefSet[i].Copy()
+                ss.BestCost = best.GetCost()
+                ss.Improvements++
+                ss.OnImprovement(&best, ss)
+                ss.LogCost(fmt.Sprintf("Improvement %-4d", ss.Improvements), ss.BestCost)
+            }
+        }
+
+        if changed {
+            continue
+        }
+
+        if ss.Rebuilds >= ss.MaxRebuilds || len(ss.DiversificationStrategies) == 0 {
+            break
+        }
+
+        ss.Rebuilds++
+        candidates := make([]T, ss.RebuildSize)
+        for i := range candidates {
+            candidates[i] = ss.RefSet[GetRandomInt(0, len(ss.RefSet)-1)].Copy()
+            for m := 0; m < ss.RebuildStrength; m++ {
+                strategy := ss.DiversificationStrategies[GetRandomInt(0, len(ss.DiversificationStrategies)-1)]
+                strategy(&candidates[i])
+            }
+            ss.HandleConstraints(&candidates[i])
+            improve(&candidates[i])
+        }
+        ss.BuildRefSet(candidates, ss.QualitySize)
+        ss.LogCost(fmt.Sprintf("Rebuild %-8d", ss.Rebuilds), best.GetCost())
+    }
+
+    if ss.Constraints != nil {
+        best = ss.Constraints.Result(best)
+    }
+    ss.LogCost("Final Solution", best.GetCost())
+    ss.LogFeasible()
+    if ss.Verbose { fmt.Println("[SS FINISHED]") }
+
+    return best
+}
@@ -0,0 +1,286 @@
+package search
+
+import (
+    "fmt"
+    "math"
+)
+
+// Anytime search
+//-------------------------------
+
+// AnytimeAlg finds a first path quickly with a heuristic inflated by Weight,
+// then keeps searching for better paths, reporting each one to OnSolution
+// with the bound on its suboptimality, until the path is proven optimal or
+// a limit is reached.
+//
+// When WeightStep is 0, it is anytime weighted A* (AWA*): the weight stays
+// the same and the search continues after each solution, pruning the nodes
+// that cannot lead to a better one. When WeightStep is positive, it is
+// anytime repairing A* (ARA*): after each solution, the weight decreases by
+// WeightStep, down to 1, and the search resumes from the states it already
+// reached, expanding each state at most once per weight.
+type AnytimeAlg[S any] struct {
+    Problem    SearchProblem[S]
+    Weight     float64
+    WeightStep float64
+    // Less orders open nodes, whose F is G + Weight*H. When nil, LessByF is
+    // used. ARA* requires the order by F.
+    Less func(a *Node[S], b *Node[S]) bool
+    // MaxExpanded stops the search after expanding that many nodes, and
+    // MaxNonImprovingExpanded after expanding that many nodes since the
+    // last solution. When 0, there is no limit.
+    MaxExpanded             int
+    MaxNonImprovingExpanded int
+    // OnSolution, when set, is called with every path better than the
+    // previous one, and by ARA* with the same path when its bound tightens.
+    OnSolution func(result Result[S])
+    Verbose    bool
+    Expanded   int
+    Generated  int
+    Solutions  int
+    // Bound is the suboptimality bound of the last path found.
+    Bound float64
+
+    lastSolution int
+}
+
+func AnytimeWeightedAStar[S any](problem SearchProblem[S], weight float64) AnytimeAlg[S] {
+    return AnytimeAlg[S]{
+        Problem: problem,
+        Weight: weight,
+        OnSolution: func(result Result[S]) {},
+    }
+}
+
+func ARAStar[S any](problem SearchProblem[S], weight float64, weightStep float64) AnytimeAlg[S] {
+    return AnytimeAlg[S]{
+        Problem: problem,
+        Weight: weight,
+        WeightStep: weightStep,
+        OnSolution: func(result Result[S]) {},
+    }
+}
+
+// limitReached reports whether a node limit stops the search.
+func (at *AnytimeAlg[S]) limitReached() bool {
+    if at.MaxExpanded > 0 && at.Expanded >= at.MaxExpanded {
+        return true
+    }
+    return at.Solutions > 0 && at.MaxNonImprovingExpanded > 0 && at.Expanded - at.lastSolution >= at.MaxNonImprovingExpanded
+}
+
+// getBound returns the suboptimality bound of a path of the given cost when
+// every better path goes through one of nodes. Their G + H is a lower bound
+// on the optimal cost as long as the heuristic is admissible.
+func getBound[S any](cost float64, nodes []*Node[S], current map[uint64]*Node[S]) float64 {
+    lower := cost
+    for _, node := range nodes {
+        if current[node.hash] == node {
+            lower = math.Min(lower, node.G + node.H)
+        }
+    }
+
+    if lower <= 0.0 {
+        if cost <= 0.0 {
+            return 1.0
+        }
+        return math.Inf(1)
+    }
+    return cost / lower
+}
+
+// report publishes goal as a new solution.
+func (at *AnytimeAlg[S]) report(goal *Node[S], limited bool) Result[S] {
+    at.Solutions++
+    at.lastSolution = at.Expanded
+
+    result := createResult(goal, at.Expanded, at.Generated, limited)
+    result.Bound = at.Bound
+    if at.Verbose { fmt.Printf("\rsolution: %-6d | cost: %-10.2f | bound: %-8.4f | expanded: %-10d\n", at.Solutions, result.Cost, result.Bound, at.Expanded) }
+    if at.OnSolution != nil {
+        at.OnSolution(result)
+    }
+    return result
+}
+
+func (at *AnytimeAlg[S]) Search() Result[S] {
+    at.Expanded = 0
+    at.Generated = 1
+    at.Solutions = 0
+    at.lastSolution = 0
+    at.Bound = math.Inf(1)
+
+    if at.WeightStep > 0.0 {
+        return at.searchARA()
+    }
+    return at.searchAWA()
+}
+
+func (at *AnytimeAlg[S]) searchAWA() Result[S] {
+    less := at.Less
+    if less == nil {
+        less = LessByF[S]
+    }
+
+    open := &nodeQueue[S]{less: less}
+    current := make(map[uint64]*Node[S])
+
+    start := createNode(at.Problem, at.Problem.Start(), nil, 0, 1.0, at.Weight)
+    current[start.hash] = start
+    open.push(start)
+
+    var incumbent *Node[S]
+    result := createResult[S](nil, 0, 1, false)
+    limited := false
+
+    // better reports whether a path through node may beat the incumbent
+    better := func(node *Node[S]) bool {
+        return incumbent == nil || node.G + node.H < incumbent.G
+    }
+
+    for open.Len() > 0 {
+        node := open.pop()
+        if current[node.hash] != node || !better(node) {
+            continue
+        }
+
+        if at.Problem.IsGoal(node.State) {
+            incumbent = node
+            at.Bound = getBound(node.G, open.nodes, current)
+            result = at.report(node, false)
+            continue
+        }
+
+        if at.limitReached() {
+            limited = true
+            break
+        }
+
+        at.Expanded++
+        if at.Verbose { logProgress(at.Expanded, open.Len(), node.F) }
+
+        for _, succ := range at.Problem.Successors(node.State) {
+            child := createNode(at.Problem, succ.State, node, succ.Cost, 1.0, at.Weight)
+            if old, ok := current[child.hash]; (ok && old.G <= child.G) || !better(child) {
+                continue
+            }
+
+            current[child.hash] = child
+            open.push(child)
+            at.Generated++
+        }
+    }
+
+    if incumbent != nil {
+        // Without a limit, every better path was pruned: the incumbent is
+        // optimal
+        at.Bound = 1.0
+        if limited {
+            at.Bound = getBound(incumbent.G, open.nodes, current)
+        }
+        result = createResult(incumbent, at.Expanded, at.Generated, limited)
+        result.Bound = at.Bound
+    }
+    result.Limited = limited
+    return result
+}
+
+func (at *AnytimeAlg[S]) searchARA() Result[S] {
+    weight := math.Max(at.Weight, 1.0)
+    open := &nodeQueue[S]{less: LessByF[S]}
+    current := make(map[uint64]*Node[S])
+    closed := make(map[uint64]bool)
+    incons := []*Node[S]{}
+
+    start := createNode(at.Problem, at.Problem.Start(), nil, 0, 1.0, weight)
+    current[start.hash] = start
+    open.push(start)
+
+    var incumbent *Node[S]
+    if at.Problem.IsGoal(start.State) {
+        incumbent = start
+    }
+
+    result := createResult[S](nil, 0, 1, false)
+    limited := false
+
+    for {
+        // Improve the path until no open node can lead to a better one
+        // under the current weight
+        for open.Len() > 0 && !limited {
+            node := open.nodes[0]
+            if current[node.hash] != node || closed[node.hash] {
+                open.pop()
+                continue
+            }
+            if incumbent != nil && incumbent.G <= node.F {
+                break
+            }
+            if at.limitReached() {
+                limited = true
+                break
+            }
+
+            open.pop()
+            closed[node.hash] = true
+            at.Expanded++
+            if at.Verbose { logProgress(at.Expanded, open.Len(), node.F) }
+
+            for _, succ := range at.Problem.Successors(node.State) {
+                child := createNode(at.Problem, succ.State, node, succ.Cost, 1.0, weight)
+                if old, ok := current[child.hash]; ok && old.G <= child.G {
+                    continue
+                }
+
+                current[child.hash] = child
+                at.Generated++
+
+                if at.Problem.IsGoal(child.State) && (incumbent == nil || child.G < incumbent.G) {
+                    incumbent = child
+                }
+
+                if closed[child.hash] {
+                    incons = append(incons, child)
+                } else {
+                    open.push(child)
+                }
+            }
+        }
+
+        if incumbent == nil {
+            break
+        }
+
+        at.Bound = math.Min(weight, getBound(incumbent.G, append(open.nodes, incons...), current))
+        if at.Solutions == 0 || incumbent.G < result.Cost || at.Bound < result.Bound {
+            result = at.report(incumbent, limited)
+        }
+
+        if weight <= 1.0 || limited {
+            break
+        }
+
+        // Decrease the weight and resume from the open and inconsistent
+        // states, with their priorities updated
+        weight = math.Max(weight - at.WeightStep, 1.0)
+        nodes := append(open.nodes, incons...)
+        queued := make(map[uint64]bool)
+        open = &nodeQueue[S]{less: LessByF[S]}
+        for _, node := range nodes {
+            if current[node.hash] == node && !queued[node.hash] {
+                node.F = node.G + weight*node.H
+                queued[node.hash] = true
+                open.push(node)
+            }
+        }
+        closed = make(map[uint64]bool)
+        incons = []*Node[S]{}
+    }
+
+    if incumbent != nil {
+        result = createResult(incumbent, at.Expanded, at.Generated, limited)
+        result.Bound = at.Bound
+    }
+    result.Limited = limited
+    return result
+}
@@ -0,0 +1,61 @@
+package search
+
+import (
+    "testing"
+)
+
+func TestAnytimeSearch(t *testing.T) {
+    problems := map[string]*graphProblem{
+        "graph": createGraphProblem(),
+        "maze": createMazeProblem(),
+    }
+
+    for name, g := range problems {
+        optimal := getOptimalCost(g)
+        algs := map[string]AnytimeAlg[int]{
+            "AWA*": AnytimeWeightedAStar[int](g, 5),
+            "ARA*": ARAStar[int](g, 5, 1),
+        }
+
+        for algName, alg := range algs {
+            label := name + " " + algName
+            solutions := []Result[int]{}
+            alg.OnSolution = func(result Result[int]) {
+                solutions = append(solutions, result)
+            }
+            result := alg.Search()
+
+            if len(solutions) == 0 {
+                t.Errorf("%s: no solution reported", label)
+                continue
+            }
+            for i, solution := range solutions {
+                checkResult(t, label, g, solution)
+                if solution.Cost > solution.Bound*optimal + 1e-9 {
+                    t.Errorf("%s: solution %d costs %g, above %g times the optimal %g", label, i, solution.Cost, solution.Bound, optimal)
+                }
+                if i == 0 {
+                    continue
+                }
+                previous := solutions[i-1]
+                if solution.Cost > previous.Cost || (solution.Cost == previous.Cost && solution.Bound >= previous.Bound) {
+                    t.Errorf("%s: solution %d, cost %g bound %g, does not improve on cost %g bound %g", label, i, solution.Cost, solution.Bound, previous.Cost, previous.Bound)
+                }
+            }
+
+            checkResult(t, label, g, result)
+            if result.Cost != optimal || result.Bound != 1.0 {
+                t.Errorf("%s: cost %g bound %g, expected %g bound 1", label, result.Cost, result.Bound, optimal)
+            }
+        }
+    }
+}
+
+func TestAnytimeSearchWithoutCallback(t *testing.T) {
+    g := createGraphProblem()
+    for _, alg := range []AnytimeAlg[int]{{Problem: g, Weight: 5}, {Problem: g, Weight: 5, WeightStep: 1}} {
+        if result := alg.Search(); result.Cost != 7 {
+            t.Errorf("cost %g, expected 7", result.Cost)
+        }
+    }
+}
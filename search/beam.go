@@ -0,0 +1,112 @@
+package search
+
+import (
+    "fmt"
+    "sort"
+)
+
+// Beam search
+//-------------------------------
+
+// BeamSearchAlg expands the states level by level, keeping only the Width
+// best nodes of each level by F = WeightG*G + WeightH*H. It trades
+// completeness and optimality for bounded memory.
+type BeamSearchAlg[S any] struct {
+    Problem SearchProblem[S]
+    Width   int
+    WeightG float64
+    WeightH float64
+    // Less orders the nodes of a level. When nil, LessByF is used.
+    Less func(a *Node[S], b *Node[S]) bool
+    // MaxDepth and MaxExpanded stop the search after that many levels or
+    // expanded nodes. When 0, there is no limit.
+    MaxDepth    int
+    MaxExpanded int
+    Verbose     bool
+    Expanded    int
+    Generated   int
+}
+
+func BeamSearch[S any](problem SearchProblem[S], width int) BeamSearchAlg[S] {
+    return BeamSearchAlg[S]{
+        Problem: problem,
+        Width: width,
+        WeightG: 1.0,
+        WeightH: 1.0,
+    }
+}
+
+// Search returns the cheapest goal of the first level that has any.
+func (bs *BeamSearchAlg[S]) Search() Result[S] {
+    less := bs.Less
+    if less == nil {
+        less = LessByF[S]
+    }
+
+    bs.Expanded = 0
+    bs.Generated = 1
+
+    start := createNode(bs.Problem, bs.Problem.Start(), nil, 0, bs.WeightG, bs.WeightH)
+    bestG := map[uint64]float64{start.hash: 0}
+    level := []*Node[S]{start}
+
+    var goal *Node[S]
+    limited := false
+
+    for len(level) > 0 {
+        for _, node := range level {
+            if bs.Problem.IsGoal(node.State) && (goal == nil || node.G < goal.G) {
+                goal = node
+            }
+        }
+        if goal != nil || limited {
+            break
+        }
+
+        if bs.MaxDepth > 0 && level[0].Depth >= bs.MaxDepth {
+            limited = true
+            break
+        }
+
+        next := []*Node[S]{}
+        // A state reached again within the level replaces its costlier node
+        inNext := make(map[uint64]int)
+        for _, node := range level {
+            if bs.MaxExpanded > 0 && bs.Expanded >= bs.MaxExpanded {
+                limited = true
+                break
+            }
+            bs.Expanded++
+
+            for _, succ := range bs.Problem.Successors(node.State) {
+                hash := bs.Problem.Hash(succ.State)
+                g := node.G + succ.Cost
+                if old, ok := bestG[hash]; ok && old <= g {
+                    continue
+                }
+
+                bestG[hash] = g
+                child := createNode(bs.Problem, succ.State, node, succ.Cost, bs.WeightG, bs.WeightH)
+                if i, ok := inNext[hash]; ok {
+                    next[i] = child
+                } else {
+                    inNext[hash] = len(next)
+                    next = append(next, child)
+                }
+                bs.Generated++
+            }
+        }
+
+        sort.SliceStable(next, func(i, j int) bool {
+            return less(next[i], next[j])
+        })
+        if len(next) > bs.Width {
+            next = next[:bs.Width]
+        }
+        level = next
+
+        if bs.Verbose && len(level) > 0 { fmt.Printf("depth: %-6d | expanded: %-10d | best f: %-10.2f\n", level[0].Depth, bs.Expanded, level[0].F) }
+    }
+
+    return createResult(goal, bs.Expanded, bs.Generated, limited)
+}
@@ -0,0 +1,122 @@
+package search
+
+import (
+    "fmt"
+)
+
+// Best-first search
+//-------------------------------
+
+// BestFirstAlg expands, at every iteration, the open node of lowest
+// priority F = WeightG*G + WeightH*H. A* weights both terms by 1, weighted
+// A* inflates the heuristic and greedy best-first ignores the cost so far.
+type BestFirstAlg[S any] struct {
+    Problem SearchProblem[S]
+    WeightG float64
+    WeightH float64
+    // Less orders open nodes. When nil, LessByF is used.
+    Less func(a *Node[S], b *Node[S]) bool
+    // Reopen expands again closed states reached by a cheaper path, which
+    // keeps A* optimal under inconsistent heuristics.
+    Reopen bool
+    // MaxExpanded stops the search after expanding that many nodes. When 0,
+    // there is no limit.
+    MaxExpanded int
+    Verbose     bool
+    Expanded    int
+    Generated   int
+}
+
+func AStar[S any](problem SearchProblem[S]) BestFirstAlg[S] {
+    return BestFirstAlg[S]{
+        Problem: problem,
+        WeightG: 1.0,
+        WeightH: 1.0,
+        Reopen: true,
+    }
+}
+
+// WeightedAStar multiplies the heuristic by weight, usually finding a path
+// faster, of cost at most weight times the optimal one.
+func WeightedAStar[S any](problem SearchProblem[S], weight float64) BestFirstAlg[S] {
+    return BestFirstAlg[S]{
+        Problem: problem,
+        WeightG: 1.0,
+        WeightH: weight,
+        Reopen: false,
+    }
+}
+
+func GreedyBestFirst[S any](problem SearchProblem[S]) BestFirstAlg[S] {
+    return BestFirstAlg[S]{
+        Problem: problem,
+        WeightG: 0.0,
+        WeightH: 1.0,
+        Reopen: false,
+    }
+}
+
+func (bf *BestFirstAlg[S]) Search() Result[S] {
+    less := bf.Less
+    if less == nil {
+        less = LessByF[S]
+    }
+
+    open := &nodeQueue[S]{less: less}
+    bestG := make(map[uint64]float64)
+    closed := make(map[uint64]bool)
+
+    bf.Expanded = 0
+    bf.Generated = 1
+
+    start := createNode(bf.Problem, bf.Problem.Start(), nil, 0, bf.WeightG, bf.WeightH)
+    bestG[start.hash] = 0
+    open.push(start)
+
+    var goal *Node[S]
+    limited := false
+
+    for open.Len() > 0 {
+        node := open.pop()
+        hash := node.hash
+
+        // Stale entries, superseded by a cheaper path to the same state
+        if node.G > bestG[hash] || (closed[hash] && !bf.Reopen) {
+            continue
+        }
+
+        if bf.Problem.IsGoal(node.State) {
+            goal = node
+            break
+        }
+
+        if bf.MaxExpanded > 0 && bf.Expanded >= bf.MaxExpanded {
+            limited = true
+            break
+        }
+
+        closed[hash] = true
+        bf.Expanded++
+        if bf.Verbose { logProgress(bf.Expanded, open.Len(), node.F) }
+
+        for _, succ := range bf.Problem.Successors(node.State) {
+            succHash := bf.Problem.Hash(succ.State)
+            g := node.G + succ.Cost
+
+            if old, ok := bestG[succHash]; ok && old <= g {
+                continue
+            }
+            if closed[succHash] && !bf.Reopen {
+                continue
+            }
+
+            bestG[succHash] = g
+            open.push(createNode(bf.Problem, succ.State, node, succ.Cost, bf.WeightG, bf.WeightH))
+            bf.Generated++
+        }
+    }
+
+    if bf.Verbose { fmt.Printf("\rexpanded: %-10d | generated: %-10d\n", bf.Expanded, bf.Generated) }
+
+    return createResult(goal, bf.Expanded, bf.Generated, limited)
+}
@@ -0,0 +1,165 @@
+package search
+
+import (
+    "fmt"
+    "math"
+)
+
+// Bidirectional search
+//-------------------------------
+
+// BidirectionalProblem is a SearchProblem whose goal states can be
+// enumerated and whose steps can be walked backward.
+type BidirectionalProblem[S any] interface {
+    SearchProblem[S]
+    Goals() []S
+    // Predecessors returns the states from which s is reachable in one
+    // step, with the cost of the step.
+    Predecessors(s S) []Successor[S]
+    // HeuristicToStart estimates the cost from the start to s.
+    HeuristicToStart(s S) float64
+}
+
+// frontier is one direction of a bidirectional search.
+type frontier[S any] struct {
+    open      *nodeQueue[S]
+    current   map[uint64]*Node[S]
+    steps     func(s S) []Successor[S]
+    heuristic func(s S) float64
+}
+
+func (fr *frontier[S]) createNode(s S, hash uint64, parent *Node[S], cost float64) *Node[S] {
+    node := &Node[S]{State: s, Parent: parent, H: fr.heuristic(s), hash: hash}
+    if parent != nil {
+        node.G = parent.G + cost
+        node.Depth = parent.Depth + 1
+    }
+    node.F = node.G + node.H
+    return node
+}
+
+// top returns the open node of lowest F, dropping the stale ones.
+func (fr *frontier[S]) top() *Node[S] {
+    for fr.open.Len() > 0 {
+        node := fr.open.nodes[0]
+        if fr.current[node.hash] == node {
+            return node
+        }
+        fr.open.pop()
+    }
+    return nil
+}
+
+// BidirectionalAlg runs A* from the start and, backward, from the goals at
+// once, expanding the smaller frontier at every iteration, until the best
+// path through a state reached by both cannot be improved. The path is
+// optimal when both heuristics are admissible.
+type BidirectionalAlg[S any] struct {
+    Problem BidirectionalProblem[S]
+    // MaxExpanded stops the search after expanding that many nodes, in both
+    // directions. When 0, there is no limit.
+    MaxExpanded int
+    Verbose     bool
+    Expanded    int
+    Generated   int
+}
+
+func Bidirectional[S any](problem BidirectionalProblem[S]) BidirectionalAlg[S] {
+    return BidirectionalAlg[S]{
+        Problem: problem,
+    }
+}
+
+func (bd *BidirectionalAlg[S]) Search() Result[S] {
+    forward := &frontier[S]{
+        open: &nodeQueue[S]{less: LessByF[S]},
+        current: make(map[uint64]*Node[S]),
+        steps: bd.Problem.Successors,
+        heuristic: bd.Problem.Heuristic,
+    }
+    backward := &frontier[S]{
+        open: &nodeQueue[S]{less: LessByF[S]},
+        current: make(map[uint64]*Node[S]),
+        steps: bd.Problem.Predecessors,
+        heuristic: bd.Problem.HeuristicToStart,
+    }
+
+    bd.Expanded = 0
+    bd.Generated = 0
+
+    // Best meeting so far: the same state reached from both ends
+    best := math.Inf(1)
+    var meetForward, meetBackward *Node[S]
+
+    add := func(fr *frontier[S], other *frontier[S], node *Node[S]) {
+        if old, ok := fr.current[node.hash]; ok && old.G <= node.G {
+            return
+        }
+        fr.current[node.hash] = node
+        fr.open.push(node)
+        bd.Generated++
+
+        if match, ok := other.current[node.hash]; ok && node.G + match.G < best {
+            best = node.G + match.G
+            meetForward, meetBackward = node, match
+            if fr == backward {
+                meetForward, meetBackward = match, node
+            }
+        }
+    }
+
+    s := bd.Problem.Start()
+    add(forward, backward, forward.createNode(s, bd.Problem.Hash(s), nil, 0))
+    for _, goal := range bd.Problem.Goals() {
+        add(backward, forward, backward.createNode(goal, bd.Problem.Hash(goal), nil, 0))
+    }
+
+    limited := false
+
+    for {
+        topForward, topBackward := forward.top(), backward.top()
+        if topForward == nil || topBackward == nil {
+            break
+        }
+
+        // No path through the open nodes can beat the best meeting
+        if best <= math.Max(topForward.F, topBackward.F) {
+            break
+        }
+
+        if bd.MaxExpanded > 0 && bd.Expanded >= bd.MaxExpanded {
+            limited = true
+            break
+        }
+
+        fr, other := forward, backward
+        if backward.open.Len() < forward.open.Len() {
+            fr, other = backward, forward
+        }
+
+        node := fr.open.pop()
+        bd.Expanded++
+        if bd.Verbose { logProgress(bd.Expanded, forward.open.Len() + backward.open.Len(), best) }
+
+        for _, step := range fr.steps(node.State) {
+            add(fr, other, fr.createNode(step.State, bd.Problem.Hash(step.State), node, step.Cost))
+        }
+    }
+
+    if bd.Verbose { fmt.Printf("\rexpanded: %-10d | generated: %-10d\n", bd.Expanded, bd.Generated) }
+
+    if meetForward == nil {
+        return createPathResult[S](nil, nil, bd.Expanded, bd.Generated, limited)
+    }
+
+    // The forward path to the meeting state, then the backward path from it
+    // to the goal
+    path := meetForward.Path()
+    costs := createResult(meetForward, 0, 0, false).Costs
+    for node := meetBackward; node.Parent != nil; node = node.Parent {
+        path = append(path, node.Parent.State)
+        costs = append(costs, node.G - node.Parent.G)
+    }
+
+    return createPathResult(path, costs, bd.Expanded, bd.Generated, limited)
+}
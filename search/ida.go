@@ -0,0 +1,104 @@
+package search
+
+import (
+    "fmt"
+    "math"
+)
+
+// Iterative deepening A*
+//-------------------------------
+
+// IDAStarAlg runs depth-first searches bounded by F = G + H, raising the
+// bound to the lowest F that exceeded it until a goal is found. It keeps
+// only the current path in memory, avoiding the states already on it.
+type IDAStarAlg[S any] struct {
+    Problem SearchProblem[S]
+    // MaxExpanded stops the search after expanding that many nodes, over
+    // all iterations. When 0, there is no limit.
+    MaxExpanded int
+    Verbose     bool
+    Expanded    int
+    Generated   int
+    Iterations  int
+    Bound       float64
+
+    onPath map[uint64]bool
+}
+
+func IDAStar[S any](problem SearchProblem[S]) IDAStarAlg[S] {
+    return IDAStarAlg[S]{
+        Problem: problem,
+    }
+}
+
+// dfs searches below node within the bound. It returns the goal found, if
+// any, and the lowest F that exceeded the bound.
+func (ida *IDAStarAlg[S]) dfs(node *Node[S]) (*Node[S], float64) {
+    if node.F > ida.Bound {
+        return nil, node.F
+    }
+    if ida.Problem.IsGoal(node.State) {
+        return node, node.F
+    }
+    if ida.MaxExpanded > 0 && ida.Expanded >= ida.MaxExpanded {
+        return nil, math.Inf(1)
+    }
+
+    ida.Expanded++
+    hash := ida.Problem.Hash(node.State)
+    ida.onPath[hash] = true
+    defer delete(ida.onPath, hash)
+
+    next := math.Inf(1)
+    for _, succ := range ida.Problem.Successors(node.State) {
+        if ida.onPath[ida.Problem.Hash(succ.State)] {
+            continue
+        }
+
+        child := &Node[S]{
+            State: succ.State,
+            Parent: node,
+            G: node.G + succ.Cost,
+            H: ida.Problem.Heuristic(succ.State),
+            Depth: node.Depth + 1,
+        }
+        child.F = child.G + child.H
+        ida.Generated++
+
+        goal, f := ida.dfs(child)
+        if goal != nil {
+            return goal, f
+        }
+        next = math.Min(next, f)
+    }
+
+    return nil, next
+}
+
+func (ida *IDAStarAlg[S]) Search() Result[S] {
+    ida.Expanded = 0
+    ida.Generated = 1
+    ida.Iterations = 0
+    ida.onPath = make(map[uint64]bool)
+
+    s := ida.Problem.Start()
+    start := &Node[S]{State: s, H: ida.Problem.Heuristic(s)}
+    start.F = start.H
+    ida.Bound = start.F
+
+    var goal *Node[S]
+    for {
+        ida.Iterations++
+        if ida.Verbose { fmt.Printf("iteration: %-6d | bound: %-10.2f | expanded: %-10d\n", ida.Iterations, ida.Bound, ida.Expanded) }
+
+        var next float64
+        goal, next = ida.dfs(start)
+        if goal != nil || math.IsInf(next, 1) {
+            break
+        }
+        ida.Bound = next
+    }
+
+    limited := goal == nil && ida.MaxExpanded > 0 && ida.Expanded >= ida.MaxExpanded
+    return createResult(goal, ida.Expanded, ida.Generated, limited)
+}
@@ -0,0 +1,394 @@
+package search
+
+import (
+    "fmt"
+    "math"
+    "math/rand"
+    "sort"
+    "sync"
+)
+
+// Monte Carlo tree search
+//-------------------------------
+
+// Parallelism selects how MCTSAlg uses its Workers.
+type Parallelism int
+
+const (
+    NoParallelism Parallelism = iota
+    // RootParallelism grows one tree per worker and keeps the best path
+    // found by any of them.
+    RootParallelism
+    // TreeParallelism grows a single tree shared by the workers. A virtual
+    // loss steers them to different branches.
+    TreeParallelism
+)
+
+// RolloutPolicy chooses which of the successors of s a playout follows.
+type RolloutPolicy[S any] func(s S, successors []Successor[S]) int
+
+// RandomRollout follows a random successor.
+func RandomRollout[S any](s S, successors []Successor[S]) int {
+    return rand.Intn(len(successors))
+}
+
+// GreedyRollout follows the successor of lowest step cost plus heuristic,
+// or a random one with probability epsilon.
+func GreedyRollout[S any](problem SearchProblem[S], epsilon float64) RolloutPolicy[S] {
+    return func(s S, successors []Successor[S]) int {
+        if rand.Float64() < epsilon {
+            return rand.Intn(len(successors))
+        }
+
+        best := 0
+        bestF := math.Inf(1)
+        for i, succ := range successors {
+            if f := succ.Cost + problem.Heuristic(succ.State); f < bestF {
+                best = i
+                bestF = f
+            }
+        }
+        return best
+    }
+}
+
+// MCTSAlg plans a path to a goal by repeated playouts. Each playout walks
+// down the tree choosing children by UCT, adds one child to the tree,
+// follows the Rollout policy from it until a goal, a dead end or MaxDepth
+// steps, and credits the nodes it walked with the reward of its cost. The
+// best path found by any playout is returned.
+//
+// Problem must be safe for concurrent use when Workers run in parallel.
+type MCTSAlg[S any] struct {
+    Problem    SearchProblem[S]
+    Iterations int
+    MaxDepth   int
+    // C weights the exploration term of UCT.
+    C       float64
+    Rollout RolloutPolicy[S]
+
+    // Playouts reaching a goal are rewarded CostScale/(CostScale + cost),
+    // the others 0. When 0, CostScale is set to the cost of the first
+    // playout that reaches a goal.
+    CostScale float64
+
+    // Progressive widening: a node visited n times has at most
+    // ceil(WideningC * n^WideningAlpha) children, the successors of lowest
+    // step cost plus heuristic first. When WideningC is 0, a child is
+    // added at every visit until all successors are in the tree.
+    WideningC     float64
+    WideningAlpha float64
+
+    // Transpositions shares the node of a state, identified by its hash,
+    // among all the paths that reach it.
+    Transpositions bool
+
+    Parallelism Parallelism
+    Workers     int
+
+    // OnSolution, when set, is called with every path better than the
+    // previous one.
+    OnSolution func(result Result[S])
+    Verbose    bool
+
+    // Playouts and Nodes count the playouts run and the tree nodes created.
+    Playouts int
+    Nodes    int
+
+    // best is the cost of the best path reported, among all trees
+    best        float64
+    reportMutex sync.Mutex
+}
+
+func MCTS[S any](problem SearchProblem[S]) MCTSAlg[S] {
+    return MCTSAlg[S]{
+        Problem: problem,
+        Iterations: 10000,
+        MaxDepth: 100,
+        C: math.Sqrt2,
+        Rollout: RandomRollout[S],
+        WideningAlpha: 0.5,
+        Transpositions: true,
+        Parallelism: NoParallelism,
+        Workers: 1,
+        OnSolution: func(result Result[S]) {},
+    }
+}
+
+type mctsNode[S any] struct {
+    state      S
+    hash       uint64
+    expanded   bool
+    successors []Successor[S] // ordered by step cost plus heuristic
+    children   []*mctsNode[S]
+    visits     int
+    reward     float64
+    // virtualLoss counts the playouts walking through the node, taken as
+    // visits without reward until they are credited
+    virtualLoss int
+}
+
+// mctsTree is the tree grown by one or several workers.
+type mctsTree[S any] struct {
+    root      *mctsNode[S]
+    table     map[uint64]*mctsNode[S]
+    best      Result[S]
+    costScale float64
+    playouts  int
+    nodes     int
+    mutex     sync.Mutex
+}
+
+func (mcts *MCTSAlg[S]) createTree() *mctsTree[S] {
+    tree := &mctsTree[S]{
+        table: make(map[uint64]*mctsNode[S]),
+        costScale: mcts.CostScale,
+    }
+    tree.root = mcts.getNode(tree, mcts.Problem.Start())
+    return tree
+}
+
+// getNode returns the node of s, shared when Transpositions is set.
+func (mcts *MCTSAlg[S]) getNode(tree *mctsTree[S], s S) *mctsNode[S] {
+    hash := mcts.Problem.Hash(s)
+    if mcts.Transpositions {
+        if node, ok := tree.table[hash]; ok {
+            return node
+        }
+    }
+
+    node := &mctsNode[S]{state: s, hash: hash}
+    tree.nodes++
+    if mcts.Transpositions {
+        tree.table[hash] = node
+    }
+    return node
+}
+
+// expand orders the successors of node, most promising first.
+func (mcts *MCTSAlg[S]) expand(node *mctsNode[S]) {
+    node.successors = mcts.Problem.Successors(node.state)
+    f := make([]float64, len(node.successors))
+    for i, succ := range node.successors {
+        f[i] = succ.Cost + mcts.Problem.Heuristic(succ.State)
+    }
+
+    order := make([]int, len(f))
+    for i := range order {
+        order[i] = i
+    }
+    sort.SliceStable(order, func(i, j int) bool { return f[order[i]] < f[order[j]] })
+
+    successors := make([]Successor[S], len(order))
+    for i, k := range order {
+        successors[i] = node.successors[k]
+    }
+    node.successors = successors
+    node.expanded = true
+}
+
+// maxChildren returns how many children node may have.
+func (mcts *MCTSAlg[S]) maxChildren(node *mctsNode[S]) int {
+    if mcts.WideningC <= 0.0 {
+        return len(node.successors)
+    }
+    n := int(math.Ceil(mcts.WideningC * math.Pow(float64(node.visits+1), mcts.WideningAlpha)))
+    if n > len(node.successors) {
+        return len(node.successors)
+    }
+    return n
+}
+
+// uct returns the child of node with the highest upper confidence bound,
+// skipping the states already on the path. It returns -1 when all of them
+// are.
+func (mcts *MCTSAlg[S]) uct(node *mctsNode[S], onPath map[uint64]bool) int {
+    parentVisits := float64(node.visits + node.virtualLoss)
+    best := -1
+    bestScore := math.Inf(-1)
+
+    for i, child := range node.children {
+        if onPath[child.hash] {
+            continue
+        }
+
+        visits := float64(child.visits + child.virtualLoss)
+        score := math.Inf(1)
+        if visits > 0 {
+            score = child.reward/visits + mcts.C*math.Sqrt(math.Log(parentVisits+1)/visits)
+        }
+        if score > bestScore {
+            best = i
+            bestScore = score
+        }
+    }
+    return best
+}
+
+// playout runs one playout on tree.
+func (mcts *MCTSAlg[S]) playout(tree *mctsTree[S]) {
+    // Selection and expansion
+    tree.mutex.Lock()
+
+    node := tree.root
+    nodes := []*mctsNode[S]{node}
+    path := []S{node.state}
+    costs := []float64{}
+    onPath := map[uint64]bool{node.hash: true}
+    node.virtualLoss++
+
+    for len(costs) < mcts.MaxDepth && !mcts.Problem.IsGoal(node.state) {
+        if !node.expanded {
+            mcts.expand(node)
+        }
+
+        var next int
+        grown := false
+        if len(node.children) < mcts.maxChildren(node) {
+            next = len(node.children)
+            node.children = append(node.children, mcts.getNode(tree, node.successors[next].State))
+            grown = true
+            // A transposition closing a cycle is left for later playouts
+            if onPath[node.children[next].hash] {
+                break
+            }
+        } else if next = mcts.uct(node, onPath); next < 0 {
+            break
+        }
+
+        costs = append(costs, node.successors[next].Cost)
+        node = node.children[next]
+        nodes = append(nodes, node)
+        path = append(path, node.state)
+        onPath[node.hash] = true
+        node.virtualLoss++
+
+        if grown {
+            break
+        }
+    }
+
+    tree.mutex.Unlock()
+
+    // Rollout
+    s := node.state
+    for !mcts.Problem.IsGoal(s) && len(costs) < mcts.MaxDepth {
+        successors := mcts.Problem.Successors(s)
+        if len(successors) == 0 {
+            break
+        }
+        succ := successors[mcts.Rollout(s, successors)]
+        s = succ.State
+        path = append(path, s)
+        costs = append(costs, succ.Cost)
+    }
+
+    // Backpropagation
+    tree.mutex.Lock()
+    defer tree.mutex.Unlock()
+
+    reward := 0.0
+    if mcts.Problem.IsGoal(s) {
+        result := createPathResult(path, costs, 0, 0, false)
+        if tree.costScale <= 0.0 {
+            tree.costScale = math.Max(result.Cost, 1.0)
+        }
+        reward = tree.costScale / (tree.costScale + result.Cost)
+
+        if !tree.best.Found || result.Cost < tree.best.Cost {
+            tree.best = result
+            mcts.report(tree)
+        }
+    }
+
+    for _, n := range nodes {
+        n.virtualLoss--
+        n.visits++
+        n.reward += reward
+    }
+    tree.playouts++
+}
+
+// report publishes the best path of tree when it is the best of all trees.
+func (mcts *MCTSAlg[S]) report(tree *mctsTree[S]) {
+    mcts.reportMutex.Lock()
+    defer mcts.reportMutex.Unlock()
+
+    if tree.best.Cost >= mcts.best {
+        return
+    }
+    mcts.best = tree.best.Cost
+    if mcts.Verbose { fmt.Printf("playout: %-10d | cost: %-10.2f | steps: %-6d\n", tree.playouts, tree.best.Cost, len(tree.best.Costs)) }
+    if mcts.OnSolution != nil {
+        mcts.OnSolution(tree.best)
+    }
+}
+
+// run runs iterations playouts on tree with the given number of workers.
+func (mcts *MCTSAlg[S]) run(tree *mctsTree[S], iterations int, workers int) {
+    var wg sync.WaitGroup
+    for w := 0; w < workers; w++ {
+        n := iterations / workers
+        if w < iterations % workers {
+            n++
+        }
+
+        wg.Add(1)
+        go func(n int) {
+            defer wg.Done()
+            for i := 0; i < n; i++ {
+                mcts.playout(tree)
+            }
+        }(n)
+    }
+    wg.Wait()
+}
+
+// Search returns the best path found, with Expanded set to the number of
+// playouts and Generated to the number of tree nodes.
+func (mcts *MCTSAlg[S]) Search() Result[S] {
+    mcts.best = math.Inf(1)
+    workers := mcts.Workers
+    if workers < 1 || mcts.Parallelism == NoParallelism {
+        workers = 1
+    }
+
+    trees := []*mctsTree[S]{}
+    if mcts.Parallelism == RootParallelism {
+        var wg sync.WaitGroup
+        for w := 0; w < workers; w++ {
+            n := mcts.Iterations / workers
+            if w < mcts.Iterations % workers {
+                n++
+            }
+
+            tree := mcts.createTree()
+            trees = append(trees, tree)
+            wg.Add(1)
+            go func(n int) {
+                defer wg.Done()
+                mcts.run(tree, n, 1)
+            }(n)
+        }
+        wg.Wait()
+    } else {
+        tree := mcts.createTree()
+        trees = append(trees, tree)
+        mcts.run(tree, mcts.Iterations, workers)
+    }
+
+    result := Result[S]{}
+    mcts.Playouts = 0
+    mcts.Nodes = 0
+    for _, tree := range trees {
+        mcts.Playouts += tree.playouts
+        mcts.Nodes += tree.nodes
+        if tree.best.Found && (!result.Found || tree.best.Cost < result.Cost) {
+            result = tree.best
+        }
+    }
+
+    result.Expanded = mcts.Playouts
+    result.Generated = mcts.Nodes
+    return result
+}
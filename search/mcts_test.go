@@ -0,0 +1,65 @@
+package search
+
+import (
+    "testing"
+)
+
+func TestMCTS(t *testing.T) {
+    cases := []struct {
+        name        string
+        parallelism Parallelism
+        workers     int
+    }{
+        {"sequential", NoParallelism, 1},
+        {"root", RootParallelism, 4},
+        {"tree", TreeParallelism, 4},
+    }
+
+    problems := map[string]*graphProblem{
+        "graph": createGraphProblem(),
+        "maze": createMazeProblem(),
+    }
+
+    for name, g := range problems {
+        optimal := getOptimalCost(g)
+        for _, c := range cases {
+            label := name + " " + c.name
+            alg := MCTS[int](g)
+            alg.Iterations = 2000
+            alg.Rollout = GreedyRollout[int](g, 0.2)
+            alg.Parallelism = c.parallelism
+            alg.Workers = c.workers
+
+            solutions := 0
+            alg.OnSolution = func(result Result[int]) {
+                solutions++
+            }
+            result := alg.Search()
+
+            checkResult(t, label, g, result)
+            if result.Cost < optimal {
+                t.Errorf("%s: cost %g below the optimal %g", label, result.Cost, optimal)
+            }
+            if solutions == 0 {
+                t.Errorf("%s: no solution reported", label)
+            }
+            if alg.Playouts != alg.Iterations {
+                t.Errorf("%s: %d playouts, expected %d", label, alg.Playouts, alg.Iterations)
+            }
+        }
+    }
+}
+
+func TestMCTSWithoutCallback(t *testing.T) {
+    g := createGraphProblem()
+    alg := MCTSAlg[int]{
+        Problem: g,
+        Iterations: 100,
+        MaxDepth: 10,
+        C: 1.0,
+        Rollout: RandomRollout[int],
+        Parallelism: TreeParallelism,
+        Workers: 2,
+    }
+    checkResult(t, "mcts", g, alg.Search())
+}
@@ -0,0 +1,162 @@
+// Package search provides generic state space search algorithms, A*,
+// weighted A*, greedy best-first, IDA* and beam search, over problems
+// described by a SearchProblem.
+package search
+
+import (
+    "container/heap"
+    "fmt"
+)
+
+// SearchProblem describes a state space. Hash identifies states: two states
+// with the same hash are the same state to the algorithms, so it must not
+// collide for the states of a search.
+type SearchProblem[S any] interface {
+    Start() S
+    IsGoal(s S) bool
+    // Successors returns the states reachable from s in one step, with the
+    // cost of the step.
+    Successors(s S) []Successor[S]
+    // Heuristic estimates the cost from s to the nearest goal. A* and IDA*
+    // find optimal paths when it never overestimates.
+    Heuristic(s S) float64
+    Hash(s S) uint64
+}
+
+type Successor[S any] struct {
+    State S
+    Cost  float64
+}
+
+// Node is a state reached by a search, with the path that reached it.
+type Node[S any] struct {
+    State  S
+    Parent *Node[S]
+    G      float64 // cost of the path from the start
+    H      float64 // heuristic estimate to the goal
+    F      float64 // priority of the node, lower first
+    Depth  int
+    hash   uint64
+    index  int
+}
+
+// Path returns the states from the start to n.
+func (n *Node[S]) Path() []S {
+    path := make([]S, n.Depth+1)
+    for node := n; node != nil; node = node.Parent {
+        path[node.Depth] = node.State
+    }
+    return path
+}
+
+// createNode returns the node of s reached from parent by a step of the
+// given cost, or the start node when parent is nil.
+func createNode[S any](problem SearchProblem[S], s S, parent *Node[S], cost float64, weightG float64, weightH float64) *Node[S] {
+    node := &Node[S]{State: s, Parent: parent, H: problem.Heuristic(s), hash: problem.Hash(s)}
+    if parent != nil {
+        node.G = parent.G + cost
+        node.Depth = parent.Depth + 1
+    }
+    node.F = weightG*node.G + weightH*node.H
+    return node
+}
+
+// Result of a search. When no goal was found, Path is empty and Cost is 0.
+type Result[S any] struct {
+    Found     bool
+    Path      []S       // states from the start to the goal
+    Costs     []float64 // cost of each step of Path
+    Cost      float64
+    Expanded  int
+    Generated int
+    // Limited tells that the search stopped at its node limit.
+    Limited bool
+    // Bound is the factor by which Cost may exceed the optimal cost, 1 when
+    // the path is proven optimal. Only anytime searches compute it.
+    Bound float64
+}
+
+func createResult[S any](goal *Node[S], expanded int, generated int, limited bool) Result[S] {
+    if goal == nil {
+        return createPathResult[S](nil, nil, expanded, generated, limited)
+    }
+
+    costs := make([]float64, goal.Depth)
+    for node := goal; node.Parent != nil; node = node.Parent {
+        costs[node.Depth-1] = node.G - node.Parent.G
+    }
+    return createPathResult(goal.Path(), costs, expanded, generated, limited)
+}
+
+// createPathResult returns the result of a search that found path, or none
+// when path is nil.
+func createPathResult[S any](path []S, costs []float64, expanded int, generated int, limited bool) Result[S] {
+    result := Result[S]{
+        Found: path != nil,
+        Path: path,
+        Costs: costs,
+        Expanded: expanded,
+        Generated: generated,
+        Limited: limited,
+    }
+    for _, cost := range costs {
+        result.Cost += cost
+    }
+    return result
+}
+
+func logProgress(expanded int, open int, f float64) {
+    if expanded % 1000 == 0 {
+        fmt.Printf("\rexpanded: %-10d | open: %-10d | f: %-10.2f", expanded, open, f)
+    }
+}
+
+// Priority queue
+//-------------------------------
+
+// nodeQueue is a heap of nodes ordered by less.
+type nodeQueue[S any] struct {
+    nodes []*Node[S]
+    less  func(a *Node[S], b *Node[S]) bool
+}
+
+func (q *nodeQueue[S]) Len() int           { return len(q.nodes) }
+func (q *nodeQueue[S]) Less(i, j int) bool { return q.less(q.nodes[i], q.nodes[j]) }
+
+func (q *nodeQueue[S]) Swap(i, j int) {
+    q.nodes[i], q.nodes[j] = q.nodes[j], q.nodes[i]
+    q.nodes[i].index = i
+    q.nodes[j].index = j
+}
+
+func (q *nodeQueue[S]) Push(x any) {
+    node := x.(*Node[S])
+    node.index = len(q.nodes)
+    q.nodes = append(q.nodes, node)
+}
+
+func (q *nodeQueue[S]) Pop() any {
+    n := len(q.nodes)
+    node := q.nodes[n-1]
+    q.nodes[n-1] = nil
+    q.nodes = q.nodes[:n-1]
+    node.index = -1
+    return node
+}
+
+func (q *nodeQueue[S]) push(node *Node[S]) {
+    heap.Push(q, node)
+}
+
+func (q *nodeQueue[S]) pop() *Node[S] {
+    return heap.Pop(q).(*Node[S])
+}
+
+// LessByF orders nodes by F, breaking ties by the lowest H, i.e. the node
+// that looks closest to the goal.
+func LessByF[S any](a *Node[S], b *Node[S]) bool {
+    if a.F == b.F {
+        return a.H < b.H
+    }
+    return a.F < b.F
+}
@@ -0,0 +1,261 @@
+package search
+
+import (
+    "math"
+    "testing"
+)
+
+// graphProblem is a directed graph with admissible heuristics toward the
+// goal and from the start.
+type graphProblem struct {
+    edges    map[int][]Successor[int]
+    start    int
+    goal     int
+    h        map[int]float64
+    hToStart map[int]float64
+}
+
+func (g *graphProblem) Start() int                        { return g.start }
+func (g *graphProblem) IsGoal(s int) bool                 { return s == g.goal }
+func (g *graphProblem) Successors(s int) []Successor[int] { return g.edges[s] }
+func (g *graphProblem) Heuristic(s int) float64           { return g.h[s] }
+func (g *graphProblem) Hash(s int) uint64                 { return uint64(s) }
+func (g *graphProblem) Goals() []int                      { return []int{g.goal} }
+func (g *graphProblem) HeuristicToStart(s int) float64    { return g.hToStart[s] }
+
+func (g *graphProblem) Predecessors(s int) []Successor[int] {
+    predecessors := []Successor[int]{}
+    for u, successors := range g.edges {
+        for _, succ := range successors {
+            if succ.State == s {
+                predecessors = append(predecessors, Successor[int]{State: u, Cost: succ.Cost})
+            }
+        }
+    }
+    return predecessors
+}
+
+// stepCost returns the cost of the step from u to v, -1 when there is none.
+func (g *graphProblem) stepCost(u int, v int) float64 {
+    for _, succ := range g.edges[u] {
+        if succ.State == v {
+            return succ.Cost
+        }
+    }
+    return -1
+}
+
+// createGraphProblem returns a graph whose optimal path, 0 1 2 3 6, costs
+// 7. The heuristics are half the true distances.
+func createGraphProblem() *graphProblem {
+    return &graphProblem{
+        edges: map[int][]Successor[int]{
+            0: {{1, 1}, {2, 4}},
+            1: {{2, 2}, {3, 5}, {5, 1}},
+            2: {{3, 1}, {4, 6}},
+            3: {{6, 3}},
+            4: {{6, 1}},
+            5: {{6, 9}},
+        },
+        start: 0,
+        goal: 6,
+        h: map[int]float64{0: 3.5, 1: 3, 2: 2, 3: 1.5, 4: 0.5, 5: 4.5, 6: 0},
+        hToStart: map[int]float64{0: 0, 1: 0.5, 2: 1.5, 3: 2, 4: 4.5, 5: 1, 6: 3.5},
+    }
+}
+
+// createMazeProblem returns the maze below as a graph with unit steps and
+// Manhattan distance heuristics, from S to G.
+func createMazeProblem() *graphProblem {
+    maze := []string{
+        "S..#....",
+        ".#.#.##.",
+        ".#...#..",
+        ".####.#.",
+        "......#.",
+        ".####.#.",
+        ".#....#.",
+        "...##..G",
+    }
+    size := len(maze)
+    g := &graphProblem{
+        edges: make(map[int][]Successor[int]),
+        start: 0,
+        goal: size*size-1,
+        h: make(map[int]float64),
+        hToStart: make(map[int]float64),
+    }
+
+    open := func(x int, y int) bool {
+        return x >= 0 && y >= 0 && x < size && y < size && maze[y][x] != '#'
+    }
+    for y := 0; y < size; y++ {
+        for x := 0; x < size; x++ {
+            if !open(x, y) {
+                continue
+            }
+            u := y*size + x
+            g.h[u] = float64(size-1-x + size-1-y)
+            g.hToStart[u] = float64(x + y)
+            for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+                if open(x+d[0], y+d[1]) {
+                    g.edges[u] = append(g.edges[u], Successor[int]{(y+d[1])*size + x+d[0], 1})
+                }
+            }
+        }
+    }
+    return g
+}
+
+// getOptimalCost returns the cost of the cheapest path from the start to
+// the goal, by uniform cost search.
+func getOptimalCost(g *graphProblem) float64 {
+    distance := map[int]float64{g.start: 0}
+    done := make(map[int]bool)
+    for {
+        u, best := -1, math.Inf(1)
+        for v, d := range distance {
+            if !done[v] && d < best {
+                u, best = v, d
+            }
+        }
+        if u < 0 {
+            return math.Inf(1)
+        }
+        if u == g.goal {
+            return best
+        }
+        done[u] = true
+        for _, succ := range g.edges[u] {
+            if d, ok := distance[succ.State]; !ok || best+succ.Cost < d {
+                distance[succ.State] = best + succ.Cost
+            }
+        }
+    }
+}
+
+// checkResult checks that result holds a path of g from the start to the
+// goal, with the costs of its steps.
+func checkResult(t *testing.T, name string, g *graphProblem, result Result[int]) {
+    t.Helper()
+    if !result.Found {
+        t.Fatalf("%s: no path found", name)
+    }
+    if result.Path[0] != g.start || result.Path[len(result.Path)-1] != g.goal {
+        t.Fatalf("%s: path %v does not lead from %d to %d", name, result.Path, g.start, g.goal)
+    }
+    if len(result.Costs) != len(result.Path)-1 {
+        t.Fatalf("%s: %d costs for a path of %d states", name, len(result.Costs), len(result.Path))
+    }
+
+    total := 0.0
+    for i := 0; i+1 < len(result.Path); i++ {
+        cost := g.stepCost(result.Path[i], result.Path[i+1])
+        if cost < 0 || cost != result.Costs[i] {
+            t.Fatalf("%s: step %d of %v costs %g, expected %g", name, i, result.Path, result.Costs[i], cost)
+        }
+        total += cost
+    }
+    if math.Abs(total - result.Cost) > 1e-9 {
+        t.Fatalf("%s: cost %g, expected %g", name, result.Cost, total)
+    }
+}
+
+func TestOptimalSearch(t *testing.T) {
+    problems := map[string]*graphProblem{
+        "graph": createGraphProblem(),
+        "maze": createMazeProblem(),
+    }
+
+    for name, g := range problems {
+        optimal := getOptimalCost(g)
+        if name == "graph" && optimal != 7 {
+            t.Fatalf("graph: optimal cost %g, expected 7", optimal)
+        }
+
+        astar := AStar[int](g)
+        ida := IDAStar[int](g)
+        bidirectional := Bidirectional[int](g)
+        results := map[string]Result[int]{
+            "A*": astar.Search(),
+            "IDA*": ida.Search(),
+            "bidirectional": bidirectional.Search(),
+        }
+
+        for alg, result := range results {
+            checkResult(t, name + " " + alg, g, result)
+            if result.Cost != optimal {
+                t.Errorf("%s %s: cost %g, expected %g", name, alg, result.Cost, optimal)
+            }
+        }
+    }
+}
+
+func TestWeightedAStar(t *testing.T) {
+    for _, g := range []*graphProblem{createGraphProblem(), createMazeProblem()} {
+        optimal := getOptimalCost(g)
+        for _, weight := range []float64{1, 1.5, 2, 5} {
+            alg := WeightedAStar[int](g, weight)
+            result := alg.Search()
+            checkResult(t, "weighted A*", g, result)
+            if result.Cost > weight*optimal + 1e-9 {
+                t.Errorf("weight %g: cost %g above %g times the optimal %g", weight, result.Cost, weight, optimal)
+            }
+        }
+    }
+}
+
+func TestSuboptimalSearch(t *testing.T) {
+    for _, g := range []*graphProblem{createGraphProblem(), createMazeProblem()} {
+        greedy := GreedyBestFirst[int](g)
+        checkResult(t, "greedy", g, greedy.Search())
+
+        for _, width := range []int{1, 2, 100} {
+            beam := BeamSearch[int](g, width)
+            checkResult(t, "beam", g, beam.Search())
+        }
+    }
+}
+
+func TestBeamSearchDuplicates(t *testing.T) {
+    // State 3 is reached twice on the second level, first from 1 then,
+    // cheaper, from 2. It is a dead end: with a beam of 2, keeping both
+    // nodes of 3 would drop 5, the only way to the goal.
+    g := &graphProblem{
+        edges: map[int][]Successor[int]{
+            0: {{1, 1}, {2, 1}},
+            1: {{3, 1}},
+            2: {{3, 0.5}, {5, 3}},
+            5: {{6, 1}},
+        },
+        start: 0,
+        goal: 6,
+        h: map[int]float64{0: 5, 1: 0, 2: 1, 5: 1},
+    }
+
+    beam := BeamSearch[int](g, 2)
+    result := beam.Search()
+    checkResult(t, "beam", g, result)
+    if result.Cost != 5 {
+        t.Errorf("cost %g, expected 5", result.Cost)
+    }
+}
+
+func TestSearchNotFound(t *testing.T) {
+    g := createGraphProblem()
+    g.goal = 7
+
+    astar := AStar[int](g)
+    ida := IDAStar[int](g)
+    beam := BeamSearch[int](g, 2)
+    results := map[string]Result[int]{
+        "A*": astar.Search(),
+        "IDA*": ida.Search(),
+        "beam": beam.Search(),
+    }
+    for alg, result := range results {
+        if result.Found || result.Limited {
+            t.Errorf("%s: found %v, limited %v on a graph without path", alg, result.Found, result.Limited)
+        }
+    }
+}
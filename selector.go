@@ -0,0 +1,360 @@
+package hx
+
+import (
+    "fmt"
+    "math"
+    "math/rand"
+)
+
+// Adaptive operator selection
+//-------------------------------
+
+// OperatorSelector chooses which of n operators to apply next and learns
+// from the reward each application earns. Rewards are non-negative,
+// typically the relative improvement the operator led to.
+type OperatorSelector interface {
+    Select(n int) int
+    Credit(op int, reward float64)
+    // GetWeights returns the probability, or the score, of each operator.
+    GetWeights() []float64
+}
+
+// OperatorStats keeps the counts and rewards every selector needs.
+type OperatorStats struct {
+    Counts  []int
+    Rewards []float64 // sum of the rewards of each operator
+}
+
+func (st *OperatorStats) ensure(n int) {
+    for len(st.Counts) < n {
+        st.Counts = append(st.Counts, 0)
+        st.Rewards = append(st.Rewards, 0.0)
+    }
+}
+
+func (st *OperatorStats) Credit(op int, reward float64) {
+    st.ensure(op+1)
+    st.Counts[op]++
+    st.Rewards[op] += reward
+}
+
+// GetMeanReward returns the mean reward of op, 0 when it was never applied.
+func (st *OperatorStats) GetMeanReward(op int) float64 {
+    if op >= len(st.Counts) || st.Counts[op] == 0 {
+        return 0.0
+    }
+    return st.Rewards[op] / float64(st.Counts[op])
+}
+
+// selectByProbability draws an operator with the given probabilities.
+func selectByProbability(p []float64) int {
+    total := 0.0
+    for _, pi := range p {
+        total += pi
+    }
+
+    r := rand.Float64() * total
+    for i, pi := range p {
+        r -= pi
+        if r < 0.0 {
+            return i
+        }
+    }
+    return len(p)-1
+}
+
+// UniformSelection chooses every operator with the same probability.
+type UniformSelection struct {
+    OperatorStats
+}
+
+func (sel *UniformSelection) Select(n int) int {
+    sel.ensure(n)
+    return GetRandomInt(0, n-1)
+}
+
+func (sel *UniformSelection) GetWeights() []float64 {
+    weights := make([]float64, len(sel.Counts))
+    for i := range weights {
+        weights[i] = 1.0 / float64(len(weights))
+    }
+    return weights
+}
+
+// RouletteSelection chooses operators with probability proportional to
+// their accumulated rewards plus MinCredit, which keeps every operator
+// selectable.
+type RouletteSelection struct {
+    OperatorStats
+    MinCredit float64
+}
+
+func (sel *RouletteSelection) Select(n int) int {
+    sel.ensure(n)
+    return selectByProbability(sel.GetWeights()[:n])
+}
+
+func (sel *RouletteSelection) GetWeights() []float64 {
+    weights := make([]float64, len(sel.Counts))
+    for i := range weights {
+        weights[i] = sel.Rewards[i] + sel.MinCredit
+    }
+    return weights
+}
+
+// getPMin returns pMin clamped to 1/n, above which the minimum
+// probabilities of n operators would add up to more than 1.
+func getPMin(pMin float64, n int) float64 {
+    return math.Min(pMin, 1.0/float64(n))
+}
+
+// ProbabilityMatching keeps a quality estimate of each operator, moved by
+// Alpha toward every reward it earns, and chooses operators with
+// probability proportional to their quality, never below PMin, at most 1/n
+// for n operators.
+type ProbabilityMatching struct {
+    OperatorStats
+    PMin    float64
+    Alpha   float64
+    Quality []float64
+}
+
+func (sel *ProbabilityMatching) ensure(n int) {
+    sel.OperatorStats.ensure(n)
+    for len(sel.Quality) < n {
+        sel.Quality = append(sel.Quality, 0.0)
+    }
+}
+
+func (sel *ProbabilityMatching) Select(n int) int {
+    sel.ensure(n)
+    return selectByProbability(sel.GetWeights()[:n])
+}
+
+func (sel *ProbabilityMatching) Credit(op int, reward float64) {
+    sel.ensure(op+1)
+    sel.OperatorStats.Credit(op, reward)
+    sel.Quality[op] += sel.Alpha * (reward - sel.Quality[op])
+}
+
+func (sel *ProbabilityMatching) GetWeights() []float64 {
+    n := len(sel.Quality)
+    total := 0.0
+    for _, q := range sel.Quality {
+        total += q
+    }
+
+    pMin := getPMin(sel.PMin, n)
+    weights := make([]float64, n)
+    for i, q := range sel.Quality {
+        if total > 0.0 {
+            weights[i] = pMin + (1.0 - float64(n)*pMin) * q / total
+        } else {
+            weights[i] = 1.0 / float64(n)
+        }
+    }
+    return weights
+}
+
+// AdaptivePursuit keeps quality estimates as ProbabilityMatching does, but
+// moves the probability of the best operator by Beta toward PMax and the
+// others toward PMin, so that the best operator is pursued faster. PMax is
+// 1-(n-1)*PMin for n operators, with PMin at most 1/n.
+type AdaptivePursuit struct {
+    OperatorStats
+    PMin        float64
+    Alpha       float64
+    Beta        float64
+    Quality     []float64
+    Probability []float64
+}
+
+func (sel *AdaptivePursuit) ensure(n int) {
+    sel.OperatorStats.ensure(n)
+    if len(sel.Quality) >= n {
+        return
+    }
+
+    for len(sel.Quality) < n {
+        sel.Quality = append(sel.Quality, 0.0)
+        sel.Probability = append(sel.Probability, 0.0)
+    }
+    for i := range sel.Probability {
+        sel.Probability[i] = 1.0 / float64(n)
+    }
+}
+
+func (sel *AdaptivePursuit) Select(n int) int {
+    sel.ensure(n)
+    return selectByProbability(sel.Probability[:n])
+}
+
+func (sel *AdaptivePursuit) Credit(op int, reward float64) {
+    sel.ensure(op+1)
+    sel.OperatorStats.Credit(op, reward)
+    sel.Quality[op] += sel.Alpha * (reward - sel.Quality[op])
+
+    n := len(sel.Quality)
+    pMin := getPMin(sel.PMin, n)
+    pMax := 1.0 - float64(n-1)*pMin
+
+    best := 0
+    for i, q := range sel.Quality {
+        if q > sel.Quality[best] {
+            best = i
+        }
+    }
+
+    for i := range sel.Probability {
+        if i == best {
+            sel.Probability[i] += sel.Beta * (pMax - sel.Probability[i])
+        } else {
+            sel.Probability[i] += sel.Beta * (pMin - sel.Probability[i])
+        }
+    }
+}
+
+func (sel *AdaptivePursuit) GetWeights() []float64 {
+    weights := make([]float64, len(sel.Probability))
+    copy(weights, sel.Probability)
+    return weights
+}
+
+// UCBSelection chooses the operator with the highest upper confidence
+// bound of its mean reward, C weighting the exploration term. Operators
+// never applied are tried first.
+type UCBSelection struct {
+    OperatorStats
+    C float64
+}
+
+func (sel *UCBSelection) Select(n int) int {
+    sel.ensure(n)
+
+    total := 0
+    for op := 0; op < n; op++ {
+        if sel.Counts[op] == 0 {
+            return op
+        }
+        total += sel.Counts[op]
+    }
+
+    best := 0
+    bestScore := math.Inf(-1)
+    for op := 0; op < n; op++ {
+        score := sel.GetMeanReward(op) + sel.C*math.Sqrt(2*math.Log(float64(total))/float64(sel.Counts[op]))
+        if score > bestScore {
+            best = op
+            bestScore = score
+        }
+    }
+    return best
+}
+
+// GetWeights returns the mean reward of each operator.
+func (sel *UCBSelection) GetWeights() []float64 {
+    weights := make([]float64, len(sel.Counts))
+    for i := range weights {
+        weights[i] = sel.GetMeanReward(i)
+    }
+    return weights
+}
+
+// EpsilonGreedy chooses a random operator with probability Epsilon and the
+// one with the highest mean reward otherwise.
+type EpsilonGreedy struct {
+    OperatorStats
+    Epsilon float64
+}
+
+func (sel *EpsilonGreedy) Select(n int) int {
+    sel.ensure(n)
+
+    if rand.Float64() < sel.Epsilon {
+        return GetRandomInt(0, n-1)
+    }
+
+    best := 0
+    for op := 1; op < n; op++ {
+        if sel.GetMeanReward(op) > sel.GetMeanReward(best) {
+            best = op
+        }
+    }
+    return best
+}
+
+// GetWeights returns the mean reward of each operator.
+func (sel *EpsilonGreedy) GetWeights() []float64 {
+    weights := make([]float64, len(sel.Counts))
+    for i := range weights {
+        weights[i] = sel.GetMeanReward(i)
+    }
+    return weights
+}
+
+// Constructors with usual parameters
+func RouletteSelector() *RouletteSelection {
+    return &RouletteSelection{MinCredit: 0.01}
+}
+
+func ProbabilityMatchingSelector() *ProbabilityMatching {
+    return &ProbabilityMatching{PMin: 0.05, Alpha: 0.3}
+}
+
+func AdaptivePursuitSelector() *AdaptivePursuit {
+    return &AdaptivePursuit{PMin: 0.05, Alpha: 0.3, Beta: 0.3}
+}
+
+func UCBSelector() *UCBSelection {
+    return &UCBSelection{C: 0.5}
+}
+
+func EpsilonGreedySelector() *EpsilonGreedy {
+    return &EpsilonGreedy{Epsilon: 0.1}
+}
+
+// GetImprovementReward returns the relative improvement from cost before
+// to cost after, 0 when it did not improve and 1 when it went from an
+// infinite cost, such as an infeasible solution under DeathPenalty, to a
+// finite one.
+func GetImprovementReward(before float64, after float64) float64 {
+    if after >= before {
+        return 0.0
+    }
+    if math.IsInf(before, 1) {
+        return 1.0
+    }
+    if math.Abs(before) < ZERO {
+        return before - after
+    }
+    return (before - after) / math.Abs(before)
+}
+
+// SelectOperator chooses one of n operators with Selector, uniformly when it
+// is nil.
+func (h *HeuristicBase[T]) SelectOperator(n int) int {
+    if h.Selector == nil {
+        return GetRandomInt(0, n-1)
+    }
+    return h.Selector.Select(n)
+}
+
+// CreditOperator rewards op with the improvement from before to after.
+func (h *HeuristicBase[T]) CreditOperator(op int, before float64, after float64) {
+    if h.Selector != nil {
+        h.Selector.Credit(op, GetImprovementReward(before, after))
+    }
+}
+
+// LogWeights logs the weights learned by Selector.
+func (h *HeuristicBase[T]) LogWeights() {
+    if h.Selector == nil || !h.Verbose {
+        return
+    }
+
+    fmt.Printf("%-16s |", "Operator Weights")
+    for _, w := range h.Selector.GetWeights() {
+        fmt.Printf(" %.4f", w)
+    }
+    fmt.Println()
+}
@@ -0,0 +1,96 @@
+// Package shunting plans the maneuvers that rearrange the rolling stock of a
+// railway yard from an initial configuration to a target one.
+//
+// A yard is made of track sections, identified by numbers, each one with two
+// ends, A and B. Rolling stock stands in rows, each one occupying one or more
+// consecutive sections. A locomotive moves a composition, the part of its row
+// on one side of it, along a path with no reversal to another section, where
+// the composition stops or couples to the row standing there.
+//
+// Instances are JSON objects:
+//
+//     {
+//         "edges": [["1B", "2A"], ["2B", "3A"], ["2B", "4A"]],
+//         "lengths": {"1": 3, "2": 3, "3": 3, "4": 2},
+//         "rolling-stock": [
+//             {"id": 0, "hp": 1500},
+//             {"id": 1, "hp": 0},
+//             {"id": 2, "hp": 0}
+//         ],
+//         "initial-state": [
+//             {"row": [0, 1], "positioning": [1]},
+//             {"row": [2], "positioning": [4]}
+//         ],
+//         "target-state": [
+//             {"row": [2, 1, 0], "positioning": [3]}
+//         ]
+//     }
+//
+// "edges" connects section ends, named by the section number followed by A
+// or B. An end connected to several others is a switch, and an end with no
+// edge is a dead end. "lengths" gives the capacity of each section, in
+// units of rolling stock, each one taking 1. Rolling stock with positive
+// horsepower "hp" are locomotives. A row lists its rolling stock ids in the
+// order of "positioning", the sections it occupies from one end of the row
+// to the other. The rolling stock of a row within a single section is listed
+// from its A end to its B end.
+package shunting
+
+import (
+    "encoding/json"
+    "io"
+    "os"
+)
+
+type RollingStock struct {
+    Id         int `json:"id"`
+    HorsePower int `json:"hp"`
+    // Group is shared by the wagons of the same target row, which are
+    // never split once coupled. Every locomotive has its own group.
+    Group int `json:"-"`
+}
+
+// RowConfig is a row of rolling stock ids and the section numbers it
+// occupies.
+type RowConfig struct {
+    Row         []int `json:"row"`
+    Positioning []int `json:"positioning"`
+}
+
+type Config struct {
+    Edges        [][]string         `json:"edges"`
+    RollingStock []RollingStock     `json:"rolling-stock"`
+    InitialState []RowConfig        `json:"initial-state"`
+    TargetState  []RowConfig        `json:"target-state"`
+    Lengths      map[string]float64 `json:"lengths"`
+}
+
+func ReadConfig(r io.Reader) (Config, error) {
+    config := Config{}
+    err := json.NewDecoder(r).Decode(&config)
+    return config, err
+}
+
+func LoadConfig(path string) (Config, error) {
+    file, err := os.Open(path)
+    if err != nil {
+        return Config{}, err
+    }
+    defer file.Close()
+    return ReadConfig(file)
+}
+
+func (config Config) Write(w io.Writer) error {
+    encoder := json.NewEncoder(w)
+    encoder.SetIndent("", "    ")
+    return encoder.Encode(config)
+}
+
+func (config Config) Save(path string) error {
+    file, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+    return config.Write(file)
+}
@@ -0,0 +1,223 @@
+package shunting
+
+import (
+    "errors"
+    "fmt"
+    "math/rand"
+    "strconv"
+)
+
+// Instance generation
+//-------------------------------
+
+var ErrGenerate = errors.New("shunting: could not generate an instance")
+
+// Generator creates random yards. The yard is a tree of Sections sections
+// grown from a lead track with a dead end, each new section connected to a
+// random free end of the others, which makes switches where an end gets
+// several connections, plus Loops edges between free ends. Sections are
+// MinLength to MaxLength long.
+//
+// Locomotives and Wagons are placed at random in the initial state. The
+// target state is reached from it by a random walk of Moves maneuvers, so
+// every instance is solvable, and Moves controls its difficulty: the walk
+// is a plan for it, which the best plan cannot be longer than.
+type Generator struct {
+    Sections    int
+    Loops       int
+    MinLength   int
+    MaxLength   int
+    Locomotives int
+    Wagons      int
+    Moves       int
+    // Seed makes instances reproducible: the same generator with the same
+    // seed creates the same instance.
+    Seed int64
+    // MaxAttempts bounds the yards and walks tried before giving up.
+    MaxAttempts int
+
+    rand *rand.Rand
+}
+
+func CreateGenerator() Generator {
+    return Generator{
+        Sections: 6,
+        Loops: 0,
+        MinLength: 2,
+        MaxLength: 5,
+        Locomotives: 1,
+        Wagons: 4,
+        Moves: 4,
+        Seed: 1,
+        MaxAttempts: 100,
+    }
+}
+
+// Generate returns a random solvable instance, and the plan of the random
+// walk that reaches its target state.
+func (g *Generator) Generate() (Config, Plan, error) {
+    if g.Sections < 1 || g.MinLength < 1 || g.MaxLength < g.MinLength || g.Locomotives < 1 || g.Wagons < 0 || g.Moves < 1 {
+        return Config{}, Plan{}, fmt.Errorf("%w: invalid parameters", ErrGenerate)
+    }
+    g.rand = rand.New(rand.NewSource(g.Seed))
+
+    for attempt := 0; attempt < g.MaxAttempts; attempt++ {
+        config := g.generateYard()
+        if !g.placeRollingStock(&config) {
+            continue
+        }
+
+        if config, plan, ok := g.walk(config); ok {
+            return config, plan, nil
+        }
+    }
+
+    return Config{}, Plan{}, fmt.Errorf("%w after %d attempts", ErrGenerate, g.MaxAttempts)
+}
+
+// generateYard returns a config with the edges and lengths of a random yard,
+// and the rolling stock, but no states.
+func (g *Generator) generateYard() Config {
+    config := Config{Lengths: make(map[string]float64)}
+
+    // The lead track, section 1, has a dead end at A
+    free := []string{"1B"}
+    config.Lengths["1"] = float64(g.MinLength + g.rand.Intn(g.MaxLength-g.MinLength+1))
+
+    for i := 2; i <= g.Sections; i++ {
+        id := strconv.Itoa(i)
+        config.Lengths[id] = float64(g.MinLength + g.rand.Intn(g.MaxLength-g.MinLength+1))
+
+        // An end may take several sections, becoming a switch
+        end := free[g.rand.Intn(len(free))]
+        config.Edges = append(config.Edges, []string{end, id + "A"})
+        free = append(free, id + "B")
+    }
+
+    for l := 0; l < g.Loops && len(free) > 1; l++ {
+        i := g.rand.Intn(len(free))
+        j := g.rand.Intn(len(free)-1)
+        if j >= i {
+            j++
+        }
+        // Ends of the same section would make a reversing loop
+        if free[i][:len(free[i])-1] == free[j][:len(free[j])-1] {
+            continue
+        }
+        config.Edges = append(config.Edges, []string{free[i], free[j]})
+    }
+
+    for i := 0; i < g.Locomotives + g.Wagons; i++ {
+        hp := 0
+        if i < g.Locomotives {
+            hp = 1000 + 500*g.rand.Intn(5)
+        }
+        config.RollingStock = append(config.RollingStock, RollingStock{Id: i, HorsePower: hp})
+    }
+
+    return config
+}
+
+// placeRollingStock sets a random initial state, one row per locomotive and
+// the remaining wagons in rows of their own, each row within a section. It
+// returns false when the rolling stock does not fit.
+func (g *Generator) placeRollingStock(config *Config) bool {
+    sections := g.rand.Perm(g.Sections)
+    wagons := g.rand.Perm(g.Wagons)
+    for i := range wagons {
+        wagons[i] += g.Locomotives
+    }
+
+    config.InitialState = nil
+    for _, s := range sections {
+        if len(wagons) == 0 && len(config.InitialState) >= g.Locomotives {
+            break
+        }
+
+        capacity := int(config.Lengths[strconv.Itoa(s+1)])
+        row := []int{}
+        if r := len(config.InitialState); r < g.Locomotives {
+            row = append(row, r)
+        }
+
+        n := g.rand.Intn(capacity - len(row) + 1)
+        if len(row) == 0 && n == 0 {
+            n = 1
+        }
+        if n > len(wagons) {
+            n = len(wagons)
+        }
+        row = append(row, wagons[:n]...)
+        wagons = wagons[n:]
+
+        // Locomotives end up at either end of their row
+        if g.rand.Intn(2) == 0 {
+            reverse(row)
+        }
+        if len(row) > 0 {
+            config.InitialState = append(config.InitialState, RowConfig{Row: row, Positioning: []int{s+1}})
+        }
+    }
+
+    return len(wagons) == 0 && len(config.InitialState) >= g.Locomotives
+}
+
+// walk applies Moves random maneuvers to the initial state of config and
+// makes the state reached its target state. It returns false when the walk
+// gets stuck, or when its plan is not valid for the final instance, whose
+// wagons grouped by the target state cannot be split.
+func (g *Generator) walk(config Config) (Config, Plan, bool) {
+    config.TargetState = config.InitialState
+    y, err := CreateYard(config)
+    if err != nil {
+        return config, Plan{}, false
+    }
+
+    // Any rolling stock may be split during the walk
+    for i := range y.RollingStock {
+        y.RollingStock[i].Group = i
+    }
+
+    plan := Plan{}
+    s := y.Initial
+    visited := map[uint64]bool{s.Hash: true}
+    for len(plan.Maneuvers) < g.Moves {
+        maneuvers := []*Maneuver{}
+        for _, m := range y.GetPossibleManeuvers(s) {
+            if !visited[m.State.Hash] {
+                maneuvers = append(maneuvers, m)
+            }
+        }
+        if len(maneuvers) == 0 {
+            return config, Plan{}, false
+        }
+
+        m := maneuvers[g.rand.Intn(len(maneuvers))]
+        plan.Maneuvers = append(plan.Maneuvers, m)
+        plan.Cost += m.Cost
+        visited[m.State.Hash] = true
+        s = m.State
+    }
+
+    config.TargetState = y.createRowConfigs(s)
+    final, err := CreateYard(config)
+    if err != nil || final.IsGoal(final.Initial) || final.ValidatePlan(plan) != nil {
+        return config, Plan{}, false
+    }
+    return config, plan, true
+}
+
+// createRowConfigs returns the rows of s as in a config.
+func (y *Yard) createRowConfigs(s State) []RowConfig {
+    rows := make([]RowConfig, len(s.Rows))
+    for r, row := range s.Rows {
+        for _, a := range row.RollingStock {
+            rows[r].Row = append(rows[r].Row, y.RollingStock[a].Id)
+        }
+        for _, u := range row.Positioning {
+            number, _ := strconv.Atoi(y.Graph.Nodes[u].Id)
+            rows[r].Positioning = append(rows[r].Positioning, number)
+        }
+    }
+    return rows
+}
@@ -0,0 +1,296 @@
+package shunting
+
+import (
+    "fmt"
+)
+
+// Maneuvers
+//-------------------------------
+
+// Maneuver moves Composition, taken from one end of a row, along Path. State
+// is the state it leads to.
+type Maneuver struct {
+    Composition []int
+    Path        Path
+    Cost        float64
+    State       State
+    // Dissimilarity of State, see Yard.GetDissimilarity
+    Dissimilarity int
+}
+
+// compositionRange is the part of a row moved by a maneuver.
+type compositionRange struct {
+    First int
+    Last  int
+}
+
+// getCompositions returns the compositions that can leave row from its
+// first end, direction 0, or its last end, direction 1: the parts of the
+// row from that end that hold a locomotive and do not split a group.
+func (y *Yard) getCompositions(row Row, direction int) []compositionRange {
+    n := len(row.RollingStock)
+    first, last := -1, -1
+    for p, a := range row.RollingStock {
+        if y.RollingStock[a].HorsePower > 0 {
+            if first < 0 {
+                first = p
+            }
+            last = p
+        }
+    }
+    if first < 0 {
+        return nil
+    }
+
+    sameGroup := func(p int, q int) bool {
+        return y.RollingStock[row.RollingStock[p]].Group == y.RollingStock[row.RollingStock[q]].Group
+    }
+
+    compositions := []compositionRange{}
+    if direction == 0 {
+        for p := first; p < n; p++ {
+            if p+1 < n && sameGroup(p, p+1) {
+                continue
+            }
+            compositions = append(compositions, compositionRange{0, p})
+        }
+    } else {
+        for p := last; p >= 0; p-- {
+            if p-1 >= 0 && sameGroup(p, p-1) {
+                continue
+            }
+            compositions = append(compositions, compositionRange{p, n-1})
+        }
+    }
+    return compositions
+}
+
+// getLeavingSide returns the end through which a composition leaves row
+// from its first end, direction 0, or its last end, direction 1. It
+// returns -1 when that end is a dead end.
+func (y *Yard) getLeavingSide(row Row, direction int) int {
+    positioning := row.Positioning
+    if len(positioning) == 1 {
+        return y.Graph.GetSide(positioning[0], "AB"[direction])
+    }
+
+    from, next := positioning[0], positioning[1]
+    if direction == 1 {
+        from, next = positioning[len(positioning)-1], positioning[len(positioning)-2]
+    }
+
+    side := y.getOppositeSide(from, next)
+    if side == 0 {
+        return -1
+    }
+    return y.Graph.GetSide(from, side)
+}
+
+// GetPossibleManeuvers returns the maneuvers possible from s.
+func (y *Yard) GetPossibleManeuvers(s State) []*Maneuver {
+    maneuvers := []*Maneuver{}
+
+    for r, row := range s.Rows {
+        for direction := 0; direction <= 1; direction++ {
+            start := y.getLeavingSide(row, direction)
+            if start < 0 {
+                continue
+            }
+            from := row.Positioning[direction*(len(row.Positioning)-1)]
+
+            for _, c := range y.getCompositions(row, direction) {
+                for _, to := range y.Sections {
+                    if to == from {
+                        continue
+                    }
+
+                    for _, path := range y.Paths[from][to] {
+                        if path.OrientationChanges > 0 || path.Nodes[1] != start {
+                            continue
+                        }
+                        if m := y.createManeuver(s, r, c, direction, path); m != nil {
+                            maneuvers = append(maneuvers, m)
+                        }
+                    }
+                }
+            }
+        }
+    }
+
+    return maneuvers
+}
+
+// createManeuver moves composition c of row r of s, leaving from the given
+// direction, along path. It returns nil when the path crosses other rows,
+// or when the composition does not fit at its end.
+func (y *Yard) createManeuver(s State, r int, c compositionRange, direction int, path Path) *Maneuver {
+    row := s.Rows[r]
+    composition := row.RollingStock[c.First:c.Last+1]
+    end := path.Nodes[len(path.Nodes)-1]
+
+    // The path must cross no row, but may end at a free end of one, to
+    // couple to it
+    merged := -1
+    mergeDirection := 0 // 0 when coupling to the first end of the row
+    for p, u := range path.Nodes[1:] {
+        if y.Graph.Nodes[u].Side != 0 {
+            continue
+        }
+
+        r2, p2 := s.GetOccupant(u)
+        if r2 < 0 {
+            continue
+        }
+        if r2 == r || u != end {
+            return nil
+        }
+
+        other := s.Rows[r2]
+        entry := y.Graph.Nodes[path.Nodes[p]].Side // p indexes path.Nodes[1:]
+        last := len(other.Positioning)-1
+        switch {
+        case last == 0 && entry == 'A':
+            mergeDirection = 0
+        case last == 0:
+            mergeDirection = 1
+        case p2 == 0 && entry == y.getOppositeSide(u, other.Positioning[1]):
+            mergeDirection = 0
+        case p2 == last && entry == y.getOppositeSide(u, other.Positioning[last-1]):
+            mergeDirection = 1
+        default:
+            return nil
+        }
+        merged = r2
+    }
+
+    // The new row, from the far end of the destination to its entrance: the
+    // row coupled to, then the composition, leading vehicle first
+    stock := []int{}
+    farToNear := []int{}
+    // The sections of the row coupled to are kept even when not all needed
+    keep := 0
+    if merged >= 0 {
+        other := s.Rows[merged]
+        if mergeDirection == 0 {
+            other = other.Reversed()
+        }
+        stock = append(stock, other.RollingStock...)
+        farToNear = append(farToNear, other.Positioning...)
+        keep = len(other.Positioning)
+    } else {
+        farToNear = append(farToNear, end)
+    }
+
+    arriving := append([]int{}, composition...)
+    if direction == 1 {
+        reverse(arriving)
+    }
+    stock = append(stock, arriving...)
+
+    for k := len(path.Nodes)-2; k >= 1; k-- {
+        if u := path.Nodes[k]; y.Graph.Nodes[u].Side == 0 {
+            farToNear = append(farToNear, u)
+        }
+    }
+
+    newRow := Row{RollingStock: stock}
+    needed := float64(len(stock))
+    for k, u := range farToNear {
+        newRow.Positioning = append(newRow.Positioning, u)
+        needed -= y.Graph.Nodes[u].Length
+        if needed <= 0 && k+1 >= keep {
+            break
+        }
+    }
+    if needed > 0 {
+        return nil
+    }
+
+    // Within a single section, rolling stock is listed from the A end, and
+    // the leading vehicle stops at the end away from the entrance
+    if len(newRow.Positioning) == 1 && y.Graph.Nodes[path.Nodes[len(path.Nodes)-2]].Side == 'A' {
+        reverse(newRow.RollingStock)
+    }
+
+    m := &Maneuver{
+        Composition: append([]int{}, composition...),
+        Path: path,
+        Cost: path.Length,
+    }
+
+    m.State.Rows = make([]Row, 0, len(s.Rows)+1)
+    for r2 := range s.Rows {
+        if r2 != r && r2 != merged {
+            m.State.Rows = append(m.State.Rows, s.Rows[r2].Copy())
+        }
+    }
+    m.State.Rows = append(m.State.Rows, newRow)
+    if len(composition) < len(row.RollingStock) {
+        m.State.Rows = append(m.State.Rows, y.getRemainder(row, c))
+    }
+
+    m.State.Hash = HashState(m.State)
+    m.Dissimilarity = y.GetDissimilarity(m.State)
+    return m
+}
+
+// getRemainder returns the part of row left behind when composition c
+// leaves, packed at the end of the row away from c.
+func (y *Yard) getRemainder(row Row, c compositionRange) Row {
+    remainder := Row{}
+    needed := 0.0
+
+    // k is the section the first rolling stock of the remainder ends up in
+    k := 0
+    if c.First == 0 {
+        remainder.RollingStock = append(remainder.RollingStock, row.RollingStock[c.Last+1:]...)
+        needed = float64(len(remainder.RollingStock))
+        for k = len(row.Positioning)-1; k >= 0; k-- {
+            remainder.Positioning = append([]int{row.Positioning[k]}, remainder.Positioning...)
+            needed -= y.Graph.Nodes[row.Positioning[k]].Length
+            if needed <= 0 {
+                break
+            }
+        }
+    } else {
+        remainder.RollingStock = append(remainder.RollingStock, row.RollingStock[:c.First]...)
+        needed = float64(len(remainder.RollingStock))
+        for _, u := range row.Positioning {
+            remainder.Positioning = append(remainder.Positioning, u)
+            needed -= y.Graph.Nodes[u].Length
+            if needed <= 0 {
+                break
+            }
+        }
+    }
+
+    // A remainder left in a single section of a longer row is listed from
+    // the A end of that section
+    if len(row.Positioning) > 1 && len(remainder.Positioning) == 1 {
+        var firstSide byte
+        if k > 0 {
+            firstSide = y.getAdjacentSide(row.Positioning[k], row.Positioning[k-1])
+        } else {
+            firstSide = y.getOppositeSide(row.Positioning[0], row.Positioning[1])
+        }
+        if firstSide == 'B' {
+            reverse(remainder.RollingStock)
+        }
+    }
+
+    return remainder
+}
+
+func (y *Yard) FormatManeuver(m *Maneuver) string {
+    ids := make([]string, len(m.Composition))
+    for i, a := range m.Composition {
+        ids[i] = fmt.Sprint(y.RollingStock[a].Id)
+    }
+
+    nodes := make([]string, len(m.Path.Nodes))
+    for i, u := range m.Path.Nodes {
+        nodes[i] = y.Graph.Nodes[u].Id
+    }
+
+    return fmt.Sprintf("move %v along %v, cost %g", ids, nodes, m.Cost)
+}
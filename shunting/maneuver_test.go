@@ -0,0 +1,124 @@
+package shunting
+
+import (
+    "testing"
+)
+
+// createTestState returns the state of the given rows, with rolling stock
+// and sections by id.
+func createTestState(t *testing.T, y *Yard, rows ...RowConfig) State {
+    t.Helper()
+    ids := make(map[int]int)
+    for a, rs := range y.RollingStock {
+        ids[rs.Id] = a
+    }
+    s, err := y.createState(rows, ids)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := y.Validate(s); err != nil {
+        t.Fatal(err)
+    }
+    return s
+}
+
+type expectedManeuver struct {
+    to    string
+    cost  float64
+    state []RowConfig
+}
+
+// checkManeuvers checks that the maneuvers possible from s are exactly the
+// expected ones.
+func checkManeuvers(t *testing.T, y *Yard, s State, expected []expectedManeuver) {
+    t.Helper()
+    maneuvers := y.GetPossibleManeuvers(s)
+    if len(maneuvers) != len(expected) {
+        for _, m := range maneuvers {
+            t.Log(y.FormatManeuver(m))
+        }
+        t.Fatalf("%d maneuvers, expected %d", len(maneuvers), len(expected))
+    }
+
+    for _, e := range expected {
+        state := createTestState(t, y, e.state...)
+        found := false
+        for _, m := range maneuvers {
+            to := y.Graph.Nodes[m.Path.Nodes[len(m.Path.Nodes)-1]].Id
+            if to == e.to && m.Cost == e.cost && m.State.Equal(state) {
+                found = true
+                break
+            }
+        }
+        if !found {
+            t.Errorf("no maneuver to %s of cost %g leading to\n%s", e.to, e.cost, y.FormatState(state))
+        }
+    }
+
+    for _, m := range maneuvers {
+        if err := y.Validate(m.State); err != nil {
+            t.Errorf("%s: %v", y.FormatManeuver(m), err)
+        }
+        if m.State.Hash != HashState(m.State) {
+            t.Errorf("%s: stale hash", y.FormatManeuver(m))
+        }
+    }
+}
+
+func TestGetPossibleManeuvers(t *testing.T) {
+    y := loadYard(t, "small")
+
+    // Locomotive 0 stands at the dead end of section 1, so it can only push
+    // its whole row through 1B
+    checkManeuvers(t, y, y.Initial, []expectedManeuver{
+        {"2", 6, []RowConfig{{[]int{0, 1}, []int{2}}, {[]int{2}, []int{4}}}},
+        {"3", 9, []RowConfig{{[]int{0, 1}, []int{3}}, {[]int{2}, []int{4}}}},
+        // Coupling to wagon 2, the row spills back into section 2
+        {"4", 8, []RowConfig{{[]int{2, 1, 0}, []int{4, 2}}}},
+    })
+}
+
+func TestGetPossibleManeuversSplit(t *testing.T) {
+    y := loadYard(t, "ladder")
+
+    // Section 3 is only left through 3A towards section 1: the other
+    // sidings would need a reversal on the switch
+    s := createTestState(t, y, RowConfig{[]int{10, 1, 2, 3, 4}, []int{3}})
+    checkManeuvers(t, y, s, []expectedManeuver{
+        {"1", 11, []RowConfig{{[]int{10}, []int{1}}, {[]int{1, 2, 3, 4}, []int{3}}}},
+        {"1", 11, []RowConfig{{[]int{10, 1}, []int{1}}, {[]int{2, 3, 4}, []int{3}}}},
+        {"1", 11, []RowConfig{{[]int{10, 1, 2}, []int{1}}, {[]int{3, 4}, []int{3}}}},
+        {"1", 11, []RowConfig{{[]int{10, 1, 2, 3}, []int{1}}, {[]int{4}, []int{3}}}},
+        {"1", 11, []RowConfig{{[]int{10, 1, 2, 3, 4}, []int{1}}}},
+    })
+}
+
+func TestGetPossibleManeuversLongRow(t *testing.T) {
+    y := loadYard(t, "loop")
+
+    // The row spans sections 5 and 4, with the locomotive at 4A. Wagons 1
+    // and 2 share a target row, so they are never split
+    s := createTestState(t, y, RowConfig{[]int{3, 2, 1, 10}, []int{5, 4}})
+    maneuvers := y.GetPossibleManeuvers(s)
+
+    state := createTestState(t, y,
+        RowConfig{[]int{10, 1, 2}, []int{3}},
+        RowConfig{[]int{3}, []int{5}},
+    )
+    found := false
+    for _, m := range maneuvers {
+        moved := map[int]bool{}
+        for _, a := range m.Composition {
+            moved[y.RollingStock[a].Id] = true
+        }
+        if moved[1] != moved[2] {
+            t.Errorf("%s splits wagons 1 and 2", y.FormatManeuver(m))
+        }
+        if m.Cost == 5 && m.State.Equal(state) {
+            found = true
+        }
+    }
+    if !found {
+        t.Errorf("no maneuver leaving wagon 3 in section 5 and the rest in section 3")
+    }
+}
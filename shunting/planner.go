@@ -0,0 +1,151 @@
+package shunting
+
+import (
+    "errors"
+    "fmt"
+
+    "github.com/nidoro/heuristix/search"
+)
+
+// Planning
+//-------------------------------
+
+var ErrNoPlan = errors.New("shunting: no plan found")
+
+// Problem is the search problem over the maneuvers of a yard. Its states
+// are maneuvers, the start one being an empty maneuver to the initial
+// state.
+type Problem struct {
+    Yard *Yard
+}
+
+func (p Problem) Start() *Maneuver {
+    return &Maneuver{
+        State: p.Yard.Initial.Copy(),
+        Dissimilarity: p.Yard.GetDissimilarity(p.Yard.Initial),
+    }
+}
+
+func (p Problem) IsGoal(m *Maneuver) bool {
+    return p.Yard.IsGoal(m.State)
+}
+
+func (p Problem) Successors(m *Maneuver) []search.Successor[*Maneuver] {
+    maneuvers := p.Yard.GetPossibleManeuvers(m.State)
+    successors := make([]search.Successor[*Maneuver], len(maneuvers))
+    for i, next := range maneuvers {
+        successors[i] = search.Successor[*Maneuver]{State: next, Cost: next.Cost}
+    }
+    return successors
+}
+
+func (p Problem) Heuristic(m *Maneuver) float64 {
+    return p.Yard.GetDistanceToTarget(m.State)
+}
+
+func (p Problem) Hash(m *Maneuver) uint64 {
+    return m.State.Hash
+}
+
+// LessByDissimilarity expands first the maneuvers closest to the target
+// state, then the ones of lowest F.
+func LessByDissimilarity(a *search.Node[*Maneuver], b *search.Node[*Maneuver]) bool {
+    if a.State.Dissimilarity == b.State.Dissimilarity {
+        return a.F < b.F
+    }
+    return a.State.Dissimilarity < b.State.Dissimilarity
+}
+
+// Plan is a sequence of maneuvers from the initial state of a yard to its
+// target state.
+type Plan struct {
+    Maneuvers []*Maneuver
+    Cost      float64
+    // Bound on the suboptimality of Cost, see search.AnytimeAlg.
+    Bound    float64
+    Expanded int
+}
+
+// Planner searches plans with anytime weighted A*, expanding first the
+// maneuvers closest to the target state, and keeps improving the plan
+// until MaxNonImprovingExpanded expansions find no better one.
+type Planner struct {
+    Yard                    *Yard
+    Weight                  float64
+    MaxExpanded             int
+    MaxNonImprovingExpanded int
+    Verbose                 bool
+    // OnPlan is called with every plan better than the previous one.
+    OnPlan func(plan Plan)
+}
+
+func CreatePlanner(yard *Yard) Planner {
+    return Planner{
+        Yard: yard,
+        Weight: 1.0,
+        MaxExpanded: 1_000_000,
+        MaxNonImprovingExpanded: 25_000,
+        OnPlan: func(plan Plan) {},
+    }
+}
+
+func createPlan(result search.Result[*Maneuver]) Plan {
+    plan := Plan{Cost: result.Cost, Bound: result.Bound, Expanded: result.Expanded}
+    // The first maneuver only holds the initial state
+    if len(result.Path) > 1 {
+        plan.Maneuvers = result.Path[1:]
+    }
+    return plan
+}
+
+// Plan returns the best plan found, or ErrNoPlan.
+func (p *Planner) Plan() (Plan, error) {
+    alg := search.AnytimeWeightedAStar[*Maneuver](Problem{p.Yard}, p.Weight)
+    alg.Less = LessByDissimilarity
+    alg.MaxExpanded = p.MaxExpanded
+    alg.MaxNonImprovingExpanded = p.MaxNonImprovingExpanded
+    alg.Verbose = p.Verbose
+    alg.OnSolution = func(result search.Result[*Maneuver]) {
+        p.OnPlan(createPlan(result))
+    }
+
+    result := alg.Search()
+    if !result.Found {
+        return Plan{Expanded: alg.Expanded}, ErrNoPlan
+    }
+    return createPlan(result), nil
+}
+
+// ValidatePlan replays plan from the initial state of the yard, checking
+// that every maneuver is possible from the state before it and that the
+// last state is the target one.
+func (y *Yard) ValidatePlan(plan Plan) error {
+    s := y.Initial
+    cost := 0.0
+
+    for i, m := range plan.Maneuvers {
+        found := false
+        for _, possible := range y.GetPossibleManeuvers(s) {
+            if possible.Cost == m.Cost && equalSlices(possible.Path.Nodes, m.Path.Nodes) && possible.State.Equal(m.State) {
+                found = true
+                break
+            }
+        }
+        if !found {
+            return fmt.Errorf("maneuver %d is not possible: %s", i, y.FormatManeuver(m))
+        }
+        if err := y.Validate(m.State); err != nil {
+            return fmt.Errorf("maneuver %d: %w", i, err)
+        }
+        s = m.State
+        cost += m.Cost
+    }
+
+    if !y.IsGoal(s) {
+        return errors.New("the plan does not reach the target state")
+    }
+    if cost != plan.Cost {
+        return fmt.Errorf("the plan costs %g, not %g", cost, plan.Cost)
+    }
+    return nil
+}
@@ -0,0 +1,80 @@
+package shunting
+
+import (
+    "testing"
+)
+
+func TestPlan(t *testing.T) {
+    // Optimal costs, checked with uniform cost search
+    cases := []struct {
+        name string
+        cost float64
+    }{
+        {"small", 23},
+        {"ladder", 96},
+        {"loop", 30},
+    }
+
+    for _, c := range cases {
+        y := loadYard(t, c.name)
+        planner := CreatePlanner(y)
+        plan, err := planner.Plan()
+        if err != nil {
+            t.Errorf("%s: %v", c.name, err)
+            continue
+        }
+        if err := y.ValidatePlan(plan); err != nil {
+            t.Errorf("%s: %v", c.name, err)
+        }
+        if plan.Cost != c.cost {
+            t.Errorf("%s: cost %g, expected %g", c.name, plan.Cost, c.cost)
+        }
+        if plan.Bound != 1.0 {
+            t.Errorf("%s: bound %g, expected 1", c.name, plan.Bound)
+        }
+    }
+}
+
+func TestValidatePlanRejects(t *testing.T) {
+    y := loadYard(t, "loop")
+    planner := CreatePlanner(y)
+    plan, err := planner.Plan()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    skipped := Plan{Maneuvers: plan.Maneuvers[1:], Cost: plan.Cost - plan.Maneuvers[0].Cost}
+    if y.ValidatePlan(skipped) == nil {
+        t.Errorf("a plan skipping its first maneuver is valid")
+    }
+
+    unfinished := Plan{Maneuvers: plan.Maneuvers[:len(plan.Maneuvers)-1]}
+    for _, m := range unfinished.Maneuvers {
+        unfinished.Cost += m.Cost
+    }
+    if y.ValidatePlan(unfinished) == nil {
+        t.Errorf("a plan not reaching the target state is valid")
+    }
+
+    wrongCost := plan
+    wrongCost.Cost++
+    if y.ValidatePlan(wrongCost) == nil {
+        t.Errorf("a plan with the wrong cost is valid")
+    }
+}
+
+func TestPlanNotFound(t *testing.T) {
+    config := loadConfig(t, "ladder")
+    // With the locomotive at the switch end, it always leads out of a
+    // siding and can never leave wagons behind
+    config.InitialState[0].Row = []int{1, 2, 3, 4, 10}
+    y, err := CreateYard(config)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    planner := CreatePlanner(y)
+    if _, err := planner.Plan(); err != ErrNoPlan {
+        t.Errorf("error %v, expected ErrNoPlan", err)
+    }
+}
@@ -0,0 +1,373 @@
+package shunting
+
+import (
+    "encoding/binary"
+    "fmt"
+    "hash/fnv"
+    "math"
+    "strings"
+)
+
+// States
+//-------------------------------
+
+// Row is rolling stock coupled together, as indices into Yard.RollingStock,
+// and the sections it occupies, in the same order. Within a single section,
+// RollingStock goes from the A end to the B end.
+type Row struct {
+    RollingStock []int
+    Positioning  []int
+}
+
+type State struct {
+    Rows []Row
+    Hash uint64
+}
+
+func (row Row) Copy() Row {
+    result := Row{
+        RollingStock: make([]int, len(row.RollingStock)),
+        Positioning: make([]int, len(row.Positioning)),
+    }
+    copy(result.RollingStock, row.RollingStock)
+    copy(result.Positioning, row.Positioning)
+    return result
+}
+
+// Reversed returns the row listed from its other end.
+func (row Row) Reversed() Row {
+    result := row.Copy()
+    reverse(result.RollingStock)
+    reverse(result.Positioning)
+    return result
+}
+
+// Equal reports whether row and row2 are the same row, possibly listed from
+// different ends when they span several sections.
+func (row Row) Equal(row2 Row) bool {
+    if equalSlices(row.RollingStock, row2.RollingStock) && equalSlices(row.Positioning, row2.Positioning) {
+        return true
+    }
+    if len(row.Positioning) < 2 {
+        return false
+    }
+    reversed := row2.Reversed()
+    return equalSlices(row.RollingStock, reversed.RollingStock) && equalSlices(row.Positioning, reversed.Positioning)
+}
+
+func (s State) Copy() State {
+    result := State{Rows: make([]Row, len(s.Rows)), Hash: s.Hash}
+    for i := range s.Rows {
+        result.Rows[i] = s.Rows[i].Copy()
+    }
+    return result
+}
+
+// Equal reports whether s and s2 have the same rows, in any order.
+func (s State) Equal(s2 State) bool {
+    if s.Hash != s2.Hash || len(s.Rows) != len(s2.Rows) {
+        return false
+    }
+
+    for _, row := range s.Rows {
+        found := false
+        for _, row2 := range s2.Rows {
+            if row.Equal(row2) {
+                found = true
+                break
+            }
+        }
+        if !found {
+            return false
+        }
+    }
+    return true
+}
+
+func hashInts(values []int, separator byte) uint64 {
+    h := fnv.New64a()
+    b := make([]byte, 8)
+    for _, v := range values {
+        binary.LittleEndian.PutUint64(b, uint64(v))
+        h.Write(b)
+    }
+    // separator to avoid collisions between concatenated slices
+    h.Write([]byte{separator})
+    return h.Sum64()
+}
+
+func hashRow(row Row) uint64 {
+    return hashInts(row.RollingStock, 0xff)*31 + hashInts(row.Positioning, 0xfe)
+}
+
+// HashState hashes the rows of s regardless of their order, and of the end
+// they are listed from.
+func HashState(s State) uint64 {
+    hash := uint64(0)
+    for _, row := range s.Rows {
+        h := hashRow(row)
+        if len(row.Positioning) > 1 {
+            if h2 := hashRow(row.Reversed()); h2 < h {
+                h = h2
+            }
+        }
+        // Sum of mixed row hashes, which does not depend on the order
+        hash += h * 0x9e3779b97f4a7c15 ^ (h >> 29)
+    }
+    return hash
+}
+
+// GetRow returns the index of the row holding rolling stock a, -1 when
+// none does.
+func (s State) GetRow(a int) int {
+    for r, row := range s.Rows {
+        if contains(row.RollingStock, a) {
+            return r
+        }
+    }
+    return -1
+}
+
+// GetOccupant returns the index of the row occupying section, and the
+// position of section in its positioning, or -1 and -1 when it is free.
+func (s State) GetOccupant(section int) (int, int) {
+    for r, row := range s.Rows {
+        for p, u := range row.Positioning {
+            if u == section {
+                return r, p
+            }
+        }
+    }
+    return -1, -1
+}
+
+// Validation
+//-------------------------------
+
+// Validate returns an error describing why s is not a valid state of the
+// yard: rolling stock missing or in several rows, empty rows, sections
+// shared by rows, rows longer than their sections, or sections of a row
+// that do not follow a path of the yard.
+func (y *Yard) Validate(s State) error {
+    count := make([]int, len(y.RollingStock))
+    occupied := make(map[int]bool)
+
+    for r, row := range s.Rows {
+        if len(row.RollingStock) == 0 || len(row.Positioning) == 0 {
+            return fmt.Errorf("row %d is empty", r)
+        }
+
+        capacity := 0.0
+        for _, a := range row.RollingStock {
+            if a < 0 || a >= len(y.RollingStock) {
+                return fmt.Errorf("row %d: unknown rolling stock index %d", r, a)
+            }
+            count[a]++
+        }
+        for _, u := range row.Positioning {
+            if u < 0 || u >= len(y.Graph.Nodes) || y.Graph.Nodes[u].Side != 0 {
+                return fmt.Errorf("row %d: %d is not a section", r, u)
+            }
+            if occupied[u] {
+                return fmt.Errorf("row %d: section %s is occupied by another row", r, y.Graph.Nodes[u].Id)
+            }
+            occupied[u] = true
+            capacity += y.Graph.Nodes[u].Length
+        }
+
+        if float64(len(row.RollingStock)) > capacity {
+            return fmt.Errorf("row %d: %d rolling stock do not fit in length %g", r, len(row.RollingStock), capacity)
+        }
+
+        if len(row.Positioning) > 1 && !y.followsPath(row.Positioning) {
+            return fmt.Errorf("row %d: sections %s are not consecutive", r, y.FormatSections(row.Positioning))
+        }
+    }
+
+    for a, c := range count {
+        if c != 1 {
+            return fmt.Errorf("rolling stock %d is in %d rows", y.RollingStock[a].Id, c)
+        }
+    }
+
+    return nil
+}
+
+// followsPath reports whether some path of the yard between the first and
+// last sections passes through exactly the given sections, in order.
+func (y *Yard) followsPath(positioning []int) bool {
+    a := positioning[0]
+    b := positioning[len(positioning)-1]
+
+    for _, path := range y.Paths[a][b] {
+        if path.OrientationChanges > 0 {
+            continue
+        }
+        sections := []int{}
+        for _, u := range path.Nodes {
+            if y.Graph.Nodes[u].Side == 0 {
+                sections = append(sections, u)
+            }
+        }
+        if equalSlices(sections, positioning) {
+            return true
+        }
+    }
+    return false
+}
+
+// Goal and estimates
+//-------------------------------
+
+// GetDissimilarity counts the rolling stock out of place in s: wagons in a
+// row with wagons of another target row, and locomotives between wagons,
+// plus the difference in the number of rows.
+func (y *Yard) GetDissimilarity(s State) int {
+    total := 0
+    for _, row := range s.Rows {
+        total += y.getRowDissimilarity(row)
+    }
+
+    rowsDiff := len(s.Rows) - len(y.Target.Rows)
+    if rowsDiff < 0 {
+        rowsDiff = -rowsDiff
+    }
+    return total + rowsDiff
+}
+
+// getRowDissimilarity counts the rolling stock of row out of place for the
+// target row it is closest to.
+func (y *Yard) getRowDissimilarity(row Row) int {
+    rowDiff := math.MaxInt
+    for _, target := range y.Target.Rows {
+        diff := 0
+        for p, a := range row.RollingStock {
+            if y.RollingStock[a].HorsePower > 0 {
+                if p != 0 && p != len(row.RollingStock)-1 {
+                    diff++
+                }
+            } else if !contains(target.RollingStock, a) {
+                diff++
+            }
+        }
+        if diff < rowDiff {
+            rowDiff = diff
+        }
+    }
+    return rowDiff
+}
+
+// overlapsTarget reports whether every rolling stock of row occupies a
+// section of its row in the target state.
+func (y *Yard) overlapsTarget(row Row) bool {
+    for _, a := range row.RollingStock {
+        target := y.Target.Rows[y.Target.GetRow(a)]
+        found := false
+        for _, u := range row.Positioning {
+            if contains(target.Positioning, u) {
+                found = true
+                break
+            }
+        }
+        if !found {
+            return false
+        }
+    }
+    return true
+}
+
+// GetDistanceToTarget returns a lower bound on the cost of the maneuvers
+// left. A row that cannot be part of the target state is only removed by a
+// maneuver leaving from or coupling to one of its end sections, which costs
+// at least the distance from that section to the closest other one. A
+// maneuver removes at most two rows, so half the sum of those distances
+// never overestimates.
+func (y *Yard) GetDistanceToTarget(s State) float64 {
+    result := 0.0
+
+    for _, row := range s.Rows {
+        if y.getRowDissimilarity(row) == 0 && y.overlapsTarget(row) {
+            continue
+        }
+
+        a := row.Positioning[0]
+        b := row.Positioning[len(row.Positioning)-1]
+        result += math.Min(y.nearest[a], y.nearest[b])
+    }
+
+    return result / 2.0
+}
+
+// IsGoal reports whether s matches the target state: every row holds the
+// wagons of a single target row, with its locomotives at the ends, there
+// are as many rows as in the target state, and every rolling stock
+// occupies a section of its row in the target state.
+func (y *Yard) IsGoal(s State) bool {
+    if y.GetDissimilarity(s) != 0 {
+        return false
+    }
+
+    for _, row := range s.Rows {
+        if !y.overlapsTarget(row) {
+            return false
+        }
+    }
+    return true
+}
+
+// Formatting
+//-------------------------------
+
+func (y *Yard) FormatSections(sections []int) string {
+    ids := make([]string, len(sections))
+    for i, u := range sections {
+        ids[i] = y.Graph.Nodes[u].Id
+    }
+    return "[" + strings.Join(ids, " ") + "]"
+}
+
+func (y *Yard) FormatRow(row Row) string {
+    ids := make([]string, len(row.RollingStock))
+    for i, a := range row.RollingStock {
+        ids[i] = fmt.Sprint(y.RollingStock[a].Id)
+    }
+    return "[" + strings.Join(ids, " ") + "] : " + y.FormatSections(row.Positioning)
+}
+
+func (y *Yard) FormatState(s State) string {
+    lines := make([]string, len(s.Rows))
+    for i, row := range s.Rows {
+        lines[i] = "  " + y.FormatRow(row)
+    }
+    return strings.Join(lines, "\n")
+}
+
+// Slice helpers
+//-------------------------------
+
+func contains(slice []int, value int) bool {
+    for _, v := range slice {
+        if v == value {
+            return true
+        }
+    }
+    return false
+}
+
+func equalSlices(a []int, b []int) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
+
+func reverse(values []int) {
+    for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+        values[i], values[j] = values[j], values[i]
+    }
+}
@@ -0,0 +1,370 @@
+package shunting
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "strconv"
+)
+
+// Yard graph
+//-------------------------------
+
+// Node is a track section, with Side 0, or one of its ends, with Side 'A'
+// or 'B'. Section is the index of the section of an end, or of the section
+// itself.
+type Node struct {
+    Id      string
+    Side    byte
+    Section int
+    Length  float64
+}
+
+// Graph links every section to its ends, and ends to the ends they connect.
+type Graph struct {
+    Nodes []Node
+    Edges [][]int
+    index map[string]int
+}
+
+// GetNodeIndex returns the index of the node of the given id, -1 when there
+// is none.
+func (g *Graph) GetNodeIndex(id string) int {
+    if index, ok := g.index[id]; ok {
+        return index
+    }
+    return -1
+}
+
+// GetSide returns the index of the end of section on the given side, -1
+// when that end connects to nothing.
+func (g *Graph) GetSide(section int, side byte) int {
+    return g.GetNodeIndex(g.Nodes[section].Id + string(side))
+}
+
+func (g *Graph) addNode(node Node) int {
+    g.index[node.Id] = len(g.Nodes)
+    g.Nodes = append(g.Nodes, node)
+    g.Edges = append(g.Edges, []int{})
+    return len(g.Nodes)-1
+}
+
+func (g *Graph) addEdge(u int, v int) {
+    for _, w := range g.Edges[u] {
+        if w == v {
+            return
+        }
+    }
+    g.Edges[u] = append(g.Edges[u], v)
+    g.Edges[v] = append(g.Edges[v], u)
+}
+
+// getEnd returns the index of the section end of the given id, creating it
+// and its section when needed.
+func (g *Graph) getEnd(id string) (int, error) {
+    if len(id) < 2 || (id[len(id)-1] != 'A' && id[len(id)-1] != 'B') {
+        return -1, fmt.Errorf("invalid section end %q", id)
+    }
+    if index := g.GetNodeIndex(id); index >= 0 {
+        return index, nil
+    }
+
+    sectionId := id[:len(id)-1]
+    section := g.GetNodeIndex(sectionId)
+    if section < 0 {
+        section = g.addNode(Node{Id: sectionId})
+        g.Nodes[section].Section = section
+    }
+
+    end := g.addNode(Node{Id: id, Side: id[len(id)-1], Section: section})
+    g.addEdge(section, end)
+    return end, nil
+}
+
+func CreateGraph(config Config) (Graph, error) {
+    g := Graph{index: make(map[string]int)}
+
+    for _, edge := range config.Edges {
+        if len(edge) != 2 {
+            return g, fmt.Errorf("edge %v does not connect two section ends", edge)
+        }
+        u, err := g.getEnd(edge[0])
+        if err != nil {
+            return g, err
+        }
+        v, err := g.getEnd(edge[1])
+        if err != nil {
+            return g, err
+        }
+        g.addEdge(u, v)
+    }
+
+    for id, length := range config.Lengths {
+        section := g.GetNodeIndex(id)
+        if section < 0 {
+            // A section with both ends unconnected
+            section = g.addNode(Node{Id: id})
+            g.Nodes[section].Section = section
+        }
+        g.Nodes[section].Length = length
+    }
+
+    return g, nil
+}
+
+// ShortestPaths runs BFS from source and returns, for every node reachable
+// from it, the path with the fewest nodes, nil for the others.
+func (g *Graph) ShortestPaths(source int) [][]int {
+    parent := make([]int, len(g.Nodes))
+    for i := range parent {
+        parent[i] = -1
+    }
+    parent[source] = source
+
+    queue := []int{source}
+    for len(queue) > 0 {
+        u := queue[0]
+        queue = queue[1:]
+
+        for _, v := range g.Edges[u] {
+            if parent[v] == -1 {
+                parent[v] = u
+                queue = append(queue, v)
+            }
+        }
+    }
+
+    paths := make([][]int, len(g.Nodes))
+    for v := range g.Nodes {
+        if parent[v] == -1 {
+            continue
+        }
+        path := []int{v}
+        for x := v; x != source; x = parent[x] {
+            path = append([]int{parent[x]}, path...)
+        }
+        paths[v] = path
+    }
+    return paths
+}
+
+// Paths
+//-------------------------------
+
+// Path between two sections, through sections and section ends.
+type Path struct {
+    Nodes []int
+    // OrientationChanges counts the reversals on the path: the ends it
+    // passes through without entering their section.
+    OrientationChanges int
+    // Length sums the lengths of the sections on the path, and of those
+    // where it reverses.
+    Length float64
+}
+
+// GetAllPaths returns the simple paths from source to target, shortest
+// first. Their number, and the time to enumerate them, grows exponentially
+// with the number of cycles of the graph, so that it suits the sparse graphs
+// of real yards, mostly ladders, but not dense ones. CreateYard calls it for
+// every pair of sections.
+func (g *Graph) GetAllPaths(source int, target int) []Path {
+    var paths []Path
+    var path []int
+    visited := make([]bool, len(g.Nodes))
+
+    var dfs func(u int)
+    dfs = func(u int) {
+        path = append(path, u)
+        visited[u] = true
+
+        if u == target {
+            pt := Path{Nodes: make([]int, len(path))}
+            copy(pt.Nodes, path)
+
+            for k, v := range path {
+                pt.Length += g.Nodes[v].Length
+                node := g.Nodes[v]
+                if node.Side != 0 && k > 0 && k < len(path)-1 && path[k-1] != node.Section && path[k+1] != node.Section {
+                    pt.OrientationChanges++
+                    pt.Length += g.Nodes[node.Section].Length
+                }
+            }
+
+            paths = append(paths, pt)
+        } else {
+            for _, v := range g.Edges[u] {
+                if !visited[v] {
+                    dfs(v)
+                }
+            }
+        }
+
+        path = path[:len(path)-1]
+        visited[u] = false
+    }
+
+    dfs(source)
+
+    sort.SliceStable(paths, func(i, j int) bool { return paths[i].Length < paths[j].Length })
+    return paths
+}
+
+// Yard
+//-------------------------------
+
+// Yard is a shunting instance: the graph of the yard, its rolling stock, the
+// initial and target states and the paths between every pair of sections.
+type Yard struct {
+    Graph        Graph
+    Sections     []int
+    RollingStock []RollingStock
+    Initial      State
+    Target       State
+    Paths        [][][]Path
+
+    // nearest is the distance from every section to the closest other one
+    nearest []float64
+}
+
+func CreateYard(config Config) (*Yard, error) {
+    g, err := CreateGraph(config)
+    if err != nil {
+        return nil, err
+    }
+
+    y := &Yard{Graph: g}
+    for i, node := range g.Nodes {
+        if node.Side == 0 {
+            y.Sections = append(y.Sections, i)
+        }
+    }
+
+    // Rows refer to rolling stock by index
+    ids := make(map[int]int)
+    for i, rs := range config.RollingStock {
+        if _, ok := ids[rs.Id]; ok {
+            return nil, fmt.Errorf("rolling stock %d is defined twice", rs.Id)
+        }
+        ids[rs.Id] = i
+        y.RollingStock = append(y.RollingStock, RollingStock{Id: rs.Id, HorsePower: rs.HorsePower})
+    }
+
+    if y.Initial, err = y.createState(config.InitialState, ids); err != nil {
+        return nil, fmt.Errorf("initial state: %w", err)
+    }
+    if y.Target, err = y.createState(config.TargetState, ids); err != nil {
+        return nil, fmt.Errorf("target state: %w", err)
+    }
+
+    // Wagons of the same target row share a group
+    nextGroup := len(y.Target.Rows)
+    for r, row := range y.Target.Rows {
+        for _, a := range row.RollingStock {
+            if y.RollingStock[a].HorsePower == 0 {
+                y.RollingStock[a].Group = r
+            } else {
+                y.RollingStock[a].Group = nextGroup
+                nextGroup++
+            }
+        }
+    }
+
+    y.Paths = make([][][]Path, len(g.Nodes))
+    for _, i := range y.Sections {
+        y.Paths[i] = make([][]Path, len(g.Nodes))
+        for _, j := range y.Sections {
+            if i != j {
+                y.Paths[i][j] = y.Graph.GetAllPaths(i, j)
+            }
+        }
+    }
+
+    y.nearest = make([]float64, len(g.Nodes))
+    for _, i := range y.Sections {
+        y.nearest[i] = math.Inf(1)
+        for _, j := range y.Sections {
+            if i != j {
+                y.nearest[i] = math.Min(y.nearest[i], y.GetDistance(i, j))
+            }
+        }
+    }
+
+    if err := y.Validate(y.Initial); err != nil {
+        return nil, fmt.Errorf("initial state: %w", err)
+    }
+    if err := y.Validate(y.Target); err != nil {
+        return nil, fmt.Errorf("target state: %w", err)
+    }
+
+    return y, nil
+}
+
+func LoadYard(path string) (*Yard, error) {
+    config, err := LoadConfig(path)
+    if err != nil {
+        return nil, err
+    }
+    return CreateYard(config)
+}
+
+func (y *Yard) createState(rows []RowConfig, ids map[int]int) (State, error) {
+    s := State{Rows: make([]Row, len(rows))}
+
+    for r, rc := range rows {
+        for _, id := range rc.Row {
+            a, ok := ids[id]
+            if !ok {
+                return s, fmt.Errorf("unknown rolling stock %d", id)
+            }
+            s.Rows[r].RollingStock = append(s.Rows[r].RollingStock, a)
+        }
+
+        for _, number := range rc.Positioning {
+            section := y.Graph.GetNodeIndex(strconv.Itoa(number))
+            if section < 0 || y.Graph.Nodes[section].Side != 0 {
+                return s, fmt.Errorf("unknown section %d", number)
+            }
+            s.Rows[r].Positioning = append(s.Rows[r].Positioning, section)
+        }
+    }
+
+    s.Hash = HashState(s)
+    return s, nil
+}
+
+// GetDistance returns the length of the shortest path between sections a
+// and b, +Inf when there is none.
+func (y *Yard) GetDistance(a int, b int) float64 {
+    if a == b {
+        return 0.0
+    }
+    if len(y.Paths[a][b]) == 0 {
+        return math.Inf(1)
+    }
+    return y.Paths[a][b][0].Length
+}
+
+// getAdjacentSide returns the side of section a facing section b, 0 when b
+// cannot be reached from a.
+func (y *Yard) getAdjacentSide(a int, b int) byte {
+    if len(y.Paths[a][b]) == 0 {
+        return 0
+    }
+    return y.Graph.Nodes[y.Paths[a][b][0].Nodes[1]].Side
+}
+
+// getOppositeSide returns the side of section a away from section b, 0 when
+// b cannot be reached from a.
+func (y *Yard) getOppositeSide(a int, b int) byte {
+    return opposite(y.getAdjacentSide(a, b))
+}
+
+func opposite(side byte) byte {
+    switch side {
+    case 'A':
+        return 'B'
+    case 'B':
+        return 'A'
+    }
+    return 0
+}
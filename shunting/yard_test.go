@@ -0,0 +1,109 @@
+package shunting
+
+import (
+    "strings"
+    "testing"
+)
+
+func loadConfig(t *testing.T, name string) Config {
+    t.Helper()
+    config, err := LoadConfig("../examples/yards/" + name + ".json")
+    if err != nil {
+        t.Fatal(err)
+    }
+    return config
+}
+
+func loadYard(t *testing.T, name string) *Yard {
+    t.Helper()
+    y, err := CreateYard(loadConfig(t, name))
+    if err != nil {
+        t.Fatal(err)
+    }
+    return y
+}
+
+func TestLoadYard(t *testing.T) {
+    for _, name := range []string{"small", "ladder", "loop"} {
+        y := loadYard(t, name)
+        if err := y.Validate(y.Initial); err != nil {
+            t.Errorf("%s: initial state: %v", name, err)
+        }
+        if err := y.Validate(y.Target); err != nil {
+            t.Errorf("%s: target state: %v", name, err)
+        }
+        if !y.IsGoal(y.Target) {
+            t.Errorf("%s: the target state is not a goal", name)
+        }
+        if y.IsGoal(y.Initial) {
+            t.Errorf("%s: the initial state is a goal", name)
+        }
+    }
+}
+
+func TestCreateYardRejects(t *testing.T) {
+    cases := []struct {
+        name   string
+        change func(config *Config)
+        err    string
+    }{
+        {"duplicate rolling stock", func(config *Config) {
+            config.RollingStock = append(config.RollingStock, RollingStock{Id: 1})
+        }, "defined twice"},
+        {"unknown rolling stock", func(config *Config) {
+            config.InitialState[1].Row = []int{7}
+        }, "unknown rolling stock 7"},
+        {"unknown section", func(config *Config) {
+            config.InitialState[1].Positioning = []int{9}
+        }, "unknown section 9"},
+        {"invalid section end", func(config *Config) {
+            config.Edges = append(config.Edges, []string{"4B", "5C"})
+        }, "invalid section end"},
+        {"overlapping rows", func(config *Config) {
+            config.InitialState[1].Positioning = []int{1}
+        }, "occupied by another row"},
+        {"row too long", func(config *Config) {
+            config.Lengths["1"] = 1
+        }, "do not fit"},
+        {"sections not consecutive", func(config *Config) {
+            config.InitialState[0].Positioning = []int{1, 3}
+        }, "not consecutive"},
+        {"missing rolling stock", func(config *Config) {
+            config.TargetState[0].Row = []int{1, 0}
+        }, "rolling stock 2 is in 0 rows"},
+    }
+
+    for _, c := range cases {
+        config := loadConfig(t, "small")
+        c.change(&config)
+        _, err := CreateYard(config)
+        if err == nil {
+            t.Errorf("%s: no error", c.name)
+        } else if !strings.Contains(err.Error(), c.err) {
+            t.Errorf("%s: error %q does not mention %q", c.name, err, c.err)
+        }
+    }
+}
+
+func TestHashState(t *testing.T) {
+    y := loadYard(t, "small")
+    s := y.Initial.Copy()
+
+    // Neither the order of the rows nor the end a row is listed from matter
+    s.Rows[0], s.Rows[1] = s.Rows[1], s.Rows[0]
+    if HashState(s) != y.Initial.Hash {
+        t.Errorf("the hash depends on the order of the rows")
+    }
+
+    long := Row{RollingStock: []int{0, 1, 2}, Positioning: []int{1, 2}}
+    a := State{Rows: []Row{long}}
+    b := State{Rows: []Row{long.Reversed()}}
+    if HashState(a) != HashState(b) {
+        t.Errorf("the hash depends on the end a row is listed from")
+    }
+
+    s.Rows[1].RollingStock = []int{1, 0}
+    if HashState(s) == y.Initial.Hash {
+        t.Errorf("the hash ignores the order of the rolling stock")
+    }
+}
@@ -0,0 +1,172 @@
+package hx
+
+import (
+    "math"
+)
+
+// Tabu memory
+//-------------------------------
+
+// TabuMemory selects what TSAlg records in its short-term memory.
+type TabuMemory int
+
+const (
+    // TabuBySolution keeps whole solutions in TabuList and forbids
+    // neighbors that Compare equal to any of them.
+    TabuBySolution TabuMemory = iota
+    // TabuByAttribute keeps the attributes of the moves made, as declared
+    // by the strategies through SetMoveAttributes, and forbids neighbors
+    // reached by a move sharing any attribute still within its tenure.
+    TabuByAttribute
+)
+
+// TabuAttribute identifies a feature of a move, e.g. "node b left route r1".
+// Key must be comparable. Tenure is the number of iterations the attribute
+// remains tabu after the move is made; zero means the tenure given by the
+// algorithm's TenurePolicy.
+type TabuAttribute struct {
+    Key    any
+    Tenure int
+}
+
+// TabuEntry is a record of the short-term memory.
+type TabuEntry struct {
+    Expiration int  // first iteration at which the entry is no longer tabu
+    Improving  bool // whether the move that created the entry was improving
+}
+
+// TabuStatus summarizes the memory entries matching a neighbor.
+type TabuStatus struct {
+    Tabu       bool
+    Expiration int  // latest expiration among the matching entries
+    Improving  bool // all matching entries were created by improving moves
+}
+
+// Long-term memory
+//-------------------------------
+
+// AttributedSolution is implemented by solutions that can describe
+// themselves as a set of attributes, e.g. the edges used by a route. TSAlg
+// counts how often each attribute appears in the visited solutions and uses
+// those frequencies to diversify the search. Attributes must be comparable.
+type AttributedSolution interface {
+    GetAttributes() []any
+}
+
+// TabuPhase is the phase TSAlg is currently running.
+type TabuPhase int
+
+const (
+    // TabuSearchPhase is the regular search driven by short-term memory.
+    TabuSearchPhase TabuPhase = iota
+    // TabuIntensificationPhase restarts from an elite solution.
+    TabuIntensificationPhase
+    // TabuDiversificationPhase penalizes frequently visited attributes.
+    TabuDiversificationPhase
+)
+
+// Aspiration criteria
+//-------------------------------
+
+// AspirationCriterion lifts the tabu status of the neighbor sl when it
+// returns true.
+type AspirationCriterion[T ComparableSolution[T]] func(ts *TSAlg[T], sl *T, status TabuStatus) bool
+
+// AspirationByObjective accepts a tabu neighbor that beats the best solution
+// found so far.
+func AspirationByObjective[T ComparableSolution[T]](ts *TSAlg[T], sl *T, status TabuStatus) bool {
+    return fitnessDiff(ts.GetFitness(&ts.BestSolution), ts.GetFitness(sl)) >= ZERO
+}
+
+// AspirationByDirection accepts an improving move whose tabu entries were
+// all created by improving moves, i.e. moves that keep the search going in
+// the same direction it was going when they became tabu.
+func AspirationByDirection[T ComparableSolution[T]](ts *TSAlg[T], sl *T, status TabuStatus) bool {
+    return status.Improving && fitnessDiff(ts.GetFitness(&ts.CurrentSolution), ts.GetFitness(sl)) >= ZERO
+}
+
+// Aspiration by default, which moves to the least tabu neighbor when every
+// neighbor is tabu, is enabled through TSAlg.DefaultAspiration.
+
+// Tenure policies
+//-------------------------------
+
+// TenurePolicy decides how long entries stay in the short-term memory.
+type TenurePolicy interface {
+    // GetTenure returns the tenure of an entry recorded now.
+    GetTenure() int
+    // Update is called once per iteration; cycle tells whether the current
+    // solution had been visited recently.
+    Update(cycle bool)
+}
+
+// ReactivePolicy is implemented by tenure policies that react to cycles.
+// TSAlg only records the solutions it visits, to detect cycles, when its
+// TenurePolicy is one that ReactsToCycles; others are updated with cycle
+// false.
+type ReactivePolicy interface {
+    TenurePolicy
+    ReactsToCycles() bool
+}
+
+// FixedTenure always returns Tenure.
+type FixedTenure struct {
+    Tenure int
+}
+
+func (p *FixedTenure) GetTenure() int {
+    return p.Tenure
+}
+
+func (p *FixedTenure) Update(cycle bool) {}
+
+// RandomTenure draws each tenure uniformly from [Min, Max].
+type RandomTenure struct {
+    Min int
+    Max int
+}
+
+func (p *RandomTenure) GetTenure() int {
+    return GetRandomInt(p.Min, p.Max)
+}
+
+func (p *RandomTenure) Update(cycle bool) {}
+
+// ReactiveTenure implements the tenure rule of reactive tabu search: the
+// tenure is multiplied by Increase whenever a cycle is detected and by
+// Decrease on every iteration without one, staying within [Min, Max].
+type ReactiveTenure struct {
+    Tenure   float64
+    Min      int
+    Max      int
+    Increase float64
+    Decrease float64
+}
+
+func ReactiveTabu(initial int, min int, max int) *ReactiveTenure {
+    return &ReactiveTenure{
+        Tenure: float64(initial),
+        Min: min,
+        Max: max,
+        Increase: 1.2,
+        Decrease: 0.98,
+    }
+}
+
+func (p *ReactiveTenure) GetTenure() int {
+    return int(math.Round(p.Tenure))
+}
+
+func (p *ReactiveTenure) ReactsToCycles() bool {
+    return true
+}
+
+func (p *ReactiveTenure) Update(cycle bool) {
+    if cycle {
+        p.Tenure = math.Max(p.Tenure*p.Increase, p.Tenure+1)
+    } else {
+        p.Tenure *= p.Decrease
+    }
+
+    p.Tenure = math.Min(math.Max(p.Tenure, float64(p.Min)), float64(p.Max))
+}
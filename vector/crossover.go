@@ -0,0 +1,52 @@
+package vector
+
+import (
+    "math/rand"
+
+    "github.com/nidoro/heuristix"
+)
+
+// Crossovers
+//-------------------------------
+// All crossovers satisfy hx.CrossoverStrategy and evaluate the child.
+
+// Uniform takes each position from either parent with equal probability.
+func Uniform(father Vector, mother Vector) Vector {
+    values := make([]int, len(father.Values))
+    for i := range values {
+        if rand.Float64() < 0.5 {
+            values[i] = father.Values[i]
+        } else {
+            values[i] = mother.Values[i]
+        }
+    }
+    return New(father.Problem, values)
+}
+
+// OnePoint takes the positions before a random cut from the father and the
+// others from the mother.
+func OnePoint(father Vector, mother Vector) Vector {
+    size := len(father.Values)
+    cut := hx.GetRandomInt(0, size)
+
+    values := make([]int, size)
+    copy(values[:cut], father.Values[:cut])
+    copy(values[cut:], mother.Values[cut:])
+    return New(father.Problem, values)
+}
+
+// TwoPoint takes the positions between two random cuts from the mother and
+// the others from the father.
+func TwoPoint(father Vector, mother Vector) Vector {
+    size := len(father.Values)
+    a := hx.GetRandomInt(0, size)
+    b := hx.GetRandomInt(0, size)
+    if a > b {
+        a, b = b, a
+    }
+
+    values := make([]int, size)
+    copy(values, father.Values)
+    copy(values[a:b], mother.Values[a:b])
+    return New(father.Problem, values)
+}
@@ -0,0 +1,257 @@
+package vector
+
+import (
+    "math/rand"
+
+    "github.com/nidoro/heuristix"
+)
+
+// Position is the tabu attribute of a changed position.
+type Position int
+
+// Move is a set of changes to a Vector. It satisfies hx.Move and
+// hx.AttributedMove.
+type Move struct {
+    Changes []Change
+
+    solution  *Vector
+    old       []int
+    delta     float64
+    evaluated bool
+}
+
+func newMove(s *Vector, changes []Change) *Move {
+    m := &Move{Changes: changes, solution: s, old: make([]int, len(changes))}
+    for c, change := range changes {
+        m.old[c] = s.Values[change.Index]
+    }
+    return m
+}
+
+// Delta returns the cost difference the move causes on the vector it was
+// generated from. It is computed once, before the move is applied.
+func (m *Move) Delta() float64 {
+    if m.evaluated {
+        return m.delta
+    }
+
+    s := m.solution
+    if s.Problem.Delta != nil {
+        m.delta = s.Problem.Delta(s.Values, m.Changes)
+    } else {
+        m.change(s.Values)
+        m.delta = s.Problem.Cost(s.Values) - s.Cost
+        m.revert(s.Values)
+    }
+
+    m.evaluated = true
+    return m.delta
+}
+
+func (m *Move) change(values []int) {
+    for _, change := range m.Changes {
+        values[change.Index] = change.Value
+    }
+}
+
+func (m *Move) revert(values []int) {
+    for c := len(m.Changes)-1; c >= 0; c-- {
+        values[m.Changes[c].Index] = m.old[c]
+    }
+}
+
+func (m *Move) Apply(s *Vector) {
+    delta := m.Delta()
+    m.change(s.Values)
+    s.Cost += delta
+}
+
+func (m *Move) Undo(s *Vector) {
+    m.revert(s.Values)
+    s.Cost -= m.delta
+}
+
+// Attributes returns the changed positions.
+func (m *Move) Attributes() []hx.TabuAttribute {
+    attributes := make([]hx.TabuAttribute, len(m.Changes))
+    for c, change := range m.Changes {
+        attributes[c] = hx.TabuAttribute{Key: Position(change.Index)}
+    }
+    return attributes
+}
+
+// Neighborhoods
+//-------------------------------
+
+// FlipNeighborhood flips K positions at once: each flipped value is
+// mirrored within its bounds, which for bitstrings is a bit flip. The
+// neighborhood is empty when K is below 1. It satisfies hx.Neighborhood.
+type FlipNeighborhood struct {
+    K int
+}
+
+func BitFlipNeighborhood() *FlipNeighborhood {
+    return &FlipNeighborhood{K: 1}
+}
+
+func KFlipNeighborhood(k int) *FlipNeighborhood {
+    return &FlipNeighborhood{K: k}
+}
+
+func (n *FlipNeighborhood) flip(s *Vector, positions []int) *Move {
+    changes := make([]Change, len(positions))
+    for c, i := range positions {
+        changes[c] = Change{Index: i, Value: s.Problem.Flipped(i, s.Values[i])}
+    }
+    return newMove(s, changes)
+}
+
+func (n *FlipNeighborhood) Moves(s *Vector, yield func(m hx.Move[Vector]) bool) {
+    size := len(s.Values)
+    if n.K > size || n.K <= 0 {
+        return
+    }
+
+    // Enumerate the combinations of K positions in lexicographic order
+    positions := make([]int, n.K)
+    for c := range positions {
+        positions[c] = c
+    }
+
+    for {
+        if !yield(n.flip(s, positions)) {
+            return
+        }
+
+        c := n.K-1
+        for c >= 0 && positions[c] == size-n.K+c {
+            c--
+        }
+        if c < 0 {
+            return
+        }
+
+        positions[c]++
+        for d := c+1; d < n.K; d++ {
+            positions[d] = positions[d-1]+1
+        }
+    }
+}
+
+func (n *FlipNeighborhood) Sample(s *Vector) (hx.Move[Vector], bool) {
+    size := len(s.Values)
+    if n.K > size || n.K <= 0 {
+        return nil, false
+    }
+
+    positions := rand.Perm(size)[:n.K]
+    return n.flip(s, positions), true
+}
+
+// AssignNeighborhood sets one position to any other value within its
+// bounds. It satisfies hx.Neighborhood.
+type AssignNeighborhood struct{}
+
+func (n *AssignNeighborhood) Moves(s *Vector, yield func(m hx.Move[Vector]) bool) {
+    for i, value := range s.Values {
+        for v := s.Problem.Lower[i]; v <= s.Problem.Upper[i]; v++ {
+            if v == value {
+                continue
+            }
+            if !yield(newMove(s, []Change{{Index: i, Value: v}})) {
+                return
+            }
+        }
+    }
+}
+
+func (n *AssignNeighborhood) Sample(s *Vector) (hx.Move[Vector], bool) {
+    for tries := 0; tries < 4*len(s.Values); tries++ {
+        i := hx.GetRandomInt(0, len(s.Values)-1)
+        if s.Problem.Lower[i] == s.Problem.Upper[i] {
+            continue
+        }
+
+        v := s.Problem.RandomValue(i)
+        for v == s.Values[i] {
+            v = s.Problem.RandomValue(i)
+        }
+        return newMove(s, []Change{{Index: i, Value: v}}), true
+    }
+
+    return nil, false
+}
+
+// SwapNeighborhood exchanges the values of two positions holding
+// different values, e.g. moves a one of a bitstring to a position holding
+// a zero. Both values must fit the bounds of the other position. It
+// satisfies hx.Neighborhood.
+type SwapNeighborhood struct{}
+
+func (n *SwapNeighborhood) swap(s *Vector, i int, j int) (*Move, bool) {
+    a := s.Values[i]
+    b := s.Values[j]
+    problem := s.Problem
+
+    if a == b || b < problem.Lower[i] || b > problem.Upper[i] || a < problem.Lower[j] || a > problem.Upper[j] {
+        return nil, false
+    }
+
+    return newMove(s, []Change{{Index: i, Value: b}, {Index: j, Value: a}}), true
+}
+
+func (n *SwapNeighborhood) Moves(s *Vector, yield func(m hx.Move[Vector]) bool) {
+    for i := 0; i < len(s.Values); i++ {
+        for j := i+1; j < len(s.Values); j++ {
+            if m, ok := n.swap(s, i, j); ok && !yield(m) {
+                return
+            }
+        }
+    }
+}
+
+func (n *SwapNeighborhood) Sample(s *Vector) (hx.Move[Vector], bool) {
+    size := len(s.Values)
+    if size < 2 {
+        return nil, false
+    }
+
+    for tries := 0; tries < 4*size; tries++ {
+        i := hx.GetRandomInt(0, size-1)
+        j := hx.GetRandomInt(0, size-1)
+        if m, ok := n.swap(s, i, j); ok {
+            return m, true
+        }
+    }
+
+    return nil, false
+}
+
+// Path relinking
+//-------------------------------
+
+// RelinkingMoves returns the changes that set a position of s to its value
+// in guide. It satisfies the RelinkingMoves of hx.PathRelinkingAlg.
+func RelinkingMoves(s *Vector, guide Vector) []hx.Move[Vector] {
+    moves := []hx.Move[Vector]{}
+
+    for i, value := range guide.Values {
+        if s.Values[i] != value {
+            moves = append(moves, newMove(s, []Change{{Index: i, Value: value}}))
+        }
+    }
+
+    return moves
+}
+
+// Distance returns the number of positions holding different values in v
+// and v2, the Hamming distance for bitstrings.
+func Distance(v Vector, v2 Vector) float64 {
+    distance := 0
+    for i := range v.Values {
+        if v.Values[i] != v2.Values[i] {
+            distance++
+        }
+    }
+    return float64(distance)
+}
@@ -0,0 +1,115 @@
+package vector
+
+import (
+    "math"
+    "testing"
+
+    "github.com/nidoro/heuristix"
+)
+
+// createTestProblem returns a problem over [0, upper]^size whose cost
+// depends on every position.
+func createTestProblem(size int, upper int) *Problem {
+    return IntegerProblem(size, 0, upper, func(values []int) float64 {
+        cost := 0.0
+        for i, v := range values {
+            cost += float64((i+1)*(v+1)*(v+1))
+        }
+        return cost
+    })
+}
+
+func checkVector(t *testing.T, name string, v Vector) {
+    t.Helper()
+    for i, value := range v.Values {
+        if value < v.Problem.Lower[i] || value > v.Problem.Upper[i] {
+            t.Fatalf("%s: position %d of %v out of bounds", name, i, v.Values)
+        }
+    }
+    if math.Abs(v.Cost - v.Problem.Cost(v.Values)) > 1e-9 {
+        t.Fatalf("%s: cost %g, expected %g", name, v.Cost, v.Problem.Cost(v.Values))
+    }
+}
+
+// checkRoundTrip applies m to s, checks the result and undoes it.
+func checkRoundTrip(t *testing.T, name string, s *Vector, m hx.Move[Vector]) {
+    t.Helper()
+    before := s.Copy()
+    m.Apply(s)
+    checkVector(t, name, *s)
+    if s.Compare(before) {
+        t.Fatalf("%s: %+v did not change %v", name, m, before.Values)
+    }
+    m.Undo(s)
+    if !s.Compare(before) || s.Cost != before.Cost {
+        t.Fatalf("%s: undo gave %v cost %g, expected %v cost %g", name, s.Values, s.Cost, before.Values, before.Cost)
+    }
+}
+
+func TestMoves(t *testing.T) {
+    const size = 6
+    bits := New(createTestProblem(size, 1), []int{1, 0, 0, 1, 1, 0})
+    integers := New(createTestProblem(size, 3), []int{0, 3, 1, 1, 2, 3})
+
+    cases := []struct {
+        name         string
+        s            Vector
+        neighborhood hx.Neighborhood[Vector]
+        count        int
+    }{
+        {"bit flip", bits, BitFlipNeighborhood(), size},
+        {"2-flip", bits, KFlipNeighborhood(2), size*(size-1)/2},
+        {"3-flip", bits, KFlipNeighborhood(3), size*(size-1)*(size-2)/6},
+        {"6-flip", bits, KFlipNeighborhood(6), 1},
+        {"7-flip", bits, KFlipNeighborhood(7), 0},
+        {"0-flip", bits, KFlipNeighborhood(0), 0},
+        {"-1-flip", bits, KFlipNeighborhood(-1), 0},
+        {"assign bits", bits, &AssignNeighborhood{}, size},
+        {"assign integers", integers, &AssignNeighborhood{}, size*3},
+        // Three ones times three zeros
+        {"swap bits", bits, &SwapNeighborhood{}, 9},
+        // Pairs of positions holding different values: 15 minus the
+        // equal pairs (1, 5) and (2, 3)
+        {"swap integers", integers, &SwapNeighborhood{}, 13},
+    }
+
+    for _, c := range cases {
+        s := c.s.Copy()
+        count := 0
+        c.neighborhood.Moves(&s, func(m hx.Move[Vector]) bool {
+            checkRoundTrip(t, c.name, &s, m)
+            count++
+            return true
+        })
+        if count != c.count {
+            t.Errorf("%s: %d moves, expected %d", c.name, count, c.count)
+        }
+    }
+}
+
+func TestSample(t *testing.T) {
+    neighborhoods := map[string]hx.Neighborhood[Vector]{
+        "bit flip": BitFlipNeighborhood(),
+        "3-flip": KFlipNeighborhood(3),
+        "assign": &AssignNeighborhood{},
+        "swap": &SwapNeighborhood{},
+    }
+
+    for name, n := range neighborhoods {
+        s := New(createTestProblem(8, 1), []int{0, 1, 0, 1, 0, 1, 0, 1})
+        for k := 0; k < 100; k++ {
+            m, ok := n.Sample(&s)
+            if !ok {
+                t.Fatalf("%s: no move sampled from %v", name, s.Values)
+            }
+            checkRoundTrip(t, name, &s, m)
+        }
+    }
+
+    s := Random(createTestProblem(4, 1))
+    for _, k := range []int{0, -1, 5} {
+        if _, ok := KFlipNeighborhood(k).Sample(&s); ok {
+            t.Errorf("%d-flip: move sampled from %d positions", k, len(s.Values))
+        }
+    }
+}
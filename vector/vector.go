@@ -0,0 +1,151 @@
+// Package vector provides bitstring and bounded integer vector solution
+// types with ready-made neighborhoods and crossovers that plug into the hx
+// algorithms.
+package vector
+
+import (
+    "encoding/binary"
+    "hash/fnv"
+
+    "github.com/nidoro/heuristix"
+)
+
+// Change sets the value at Index to Value.
+type Change struct {
+    Index int
+    Value int
+}
+
+// Problem holds what all the vectors of a problem share. Position i takes
+// values in [Lower[i], Upper[i]]; bitstrings have bounds [0, 1]. Cost is the
+// objective function. Delta, when set, returns the cost difference the
+// changes cause on values without making them; otherwise moves are
+// evaluated by making them, calling Cost and undoing them.
+type Problem struct {
+    Size  int
+    Lower []int
+    Upper []int
+    Cost  func(values []int) float64
+    Delta func(values []int, changes []Change) float64
+}
+
+// BinaryProblem creates a problem over bitstrings of the given size.
+func BinaryProblem(size int, cost func(values []int) float64) *Problem {
+    return IntegerProblem(size, 0, 1, cost)
+}
+
+// IntegerProblem creates a problem over vectors whose positions all take
+// values in [lower, upper].
+func IntegerProblem(size int, lower int, upper int, cost func(values []int) float64) *Problem {
+    problem := &Problem{
+        Size: size,
+        Lower: make([]int, size),
+        Upper: make([]int, size),
+        Cost: cost,
+    }
+    for i := 0; i < size; i++ {
+        problem.Lower[i] = lower
+        problem.Upper[i] = upper
+    }
+    return problem
+}
+
+// Vector is an assignment of a bounded integer to every position. It
+// satisfies hx.ComparableSolution, hx.HashableSolution and
+// hx.AttributedSolution.
+type Vector struct {
+    Problem *Problem
+    Values  []int
+    Cost    float64
+}
+
+// New creates a vector with the given values and evaluates its cost.
+func New(problem *Problem, values []int) Vector {
+    v := Vector{
+        Problem: problem,
+        Values: make([]int, len(values)),
+    }
+    copy(v.Values, values)
+    v.Evaluate()
+    return v
+}
+
+// Lowest creates the vector with every position at its lower bound, e.g.
+// the all-zeros bitstring.
+func Lowest(problem *Problem) Vector {
+    values := make([]int, problem.Size)
+    copy(values, problem.Lower)
+    return New(problem, values)
+}
+
+// Random creates a vector with uniformly random values within the bounds.
+func Random(problem *Problem) Vector {
+    values := make([]int, problem.Size)
+    for i := range values {
+        values[i] = problem.RandomValue(i)
+    }
+    return New(problem, values)
+}
+
+// RandomValue returns a uniformly random value for position i.
+func (problem *Problem) RandomValue(i int) int {
+    return problem.Lower[i] + hx.GetRandomInt(0, problem.Upper[i]-problem.Lower[i])
+}
+
+// Flipped returns value mirrored within the bounds of position i, which for
+// bitstrings is a bit flip.
+func (problem *Problem) Flipped(i int, value int) int {
+    return problem.Lower[i] + problem.Upper[i] - value
+}
+
+// Evaluate recomputes the cost with the problem's cost function.
+func (v *Vector) Evaluate() {
+    v.Cost = v.Problem.Cost(v.Values)
+}
+
+func (v Vector) GetCost() float64 {
+    return v.Cost
+}
+
+func (v Vector) Copy() Vector {
+    result := v
+    result.Values = make([]int, len(v.Values))
+    copy(result.Values, v.Values)
+    return result
+}
+
+func (v Vector) Compare(v2 Vector) bool {
+    if len(v.Values) != len(v2.Values) {
+        return false
+    }
+
+    for i := range v.Values {
+        if v.Values[i] != v2.Values[i] {
+            return false
+        }
+    }
+
+    return true
+}
+
+func (v Vector) Hash() uint64 {
+    h := fnv.New64a()
+    b := make([]byte, 8)
+
+    for _, value := range v.Values {
+        binary.LittleEndian.PutUint64(b, uint64(value))
+        h.Write(b)
+    }
+
+    return h.Sum64()
+}
+
+// GetAttributes returns one Change per position, so tabu search can count
+// how often each position held each value.
+func (v Vector) GetAttributes() []any {
+    attributes := make([]any, len(v.Values))
+    for i, value := range v.Values {
+        attributes[i] = Change{Index: i, Value: value}
+    }
+    return attributes
+}